@@ -0,0 +1,284 @@
+package local
+
+import (
+	"context"
+)
+
+// GetByKeysBatch must get items by keys at once(e.g, WHERE rowid = ANY($1)).
+//
+// Items may be passed in any order; missing keys must be ignored.
+// An item passed to found may be kept until the batch is consumed;
+// its memory(e.g, the backing array of a slice) must not be reused by the getter.
+//
+// # Arguments
+//   - ctx: A context.
+//   - con: A data store connection.
+//   - bucket: A bucket which may have items to get.
+//   - keys: The keys of items to get.
+//   - filter: The filter which may be used to get or skip getting items.
+//   - found: Saves an item with its key.
+type GetByKeysBatch[D, B, F, K, V any] func(
+	ctx context.Context,
+	con D,
+	bucket *B,
+	keys []K,
+	filter *F,
+	found func(key K, val *V) error,
+) error
+
+// GetByKeysBatchFromKey creates a GetByKeysBatch which calls getByKey for each key.
+//
+// Each key uses a fresh buffer so that getByKey may reuse the memory of its buffer.
+// The GetByKeysBatch is safe for concurrent use if getByKey is.
+func GetByKeysBatchFromKey[D, B, F, K, V any](
+	getByKey GetByKey[D, B, F, K, V],
+) GetByKeysBatch[D, B, F, K, V] {
+	return func(
+		ctx context.Context,
+		con D,
+		bucket *B,
+		keys []K,
+		filter *F,
+		found func(key K, val *V) error,
+	) error {
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for _, key := range keys {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+			var buf V
+			got, e := getByKey(ctx, con, bucket, key, &buf, filter)
+			if nil != e {
+				return e
+			}
+			if !got {
+				continue
+			}
+			e = found(key, &buf)
+			if nil != e {
+				return e
+			}
+		}
+		return nil
+	}
+}
+
+// GetByKeysBatchDecodedNew creates a new GetByKeysBatch which gets decoded values.
+//
+// A decoded value is created for each found item;
+// the GetByKeysBatch is safe for concurrent use if getEncoded, decoder and filterDecoded are.
+//
+// # Arguments
+//   - getEncoded: Gets encoded values.
+//   - decoder: Gets a decoded value from an encoded value.
+//   - filterDecoded: Checks if a decoded item must be used or not.
+func GetByKeysBatchDecodedNew[G, B, F, K, E, D any](
+	getEncoded GetByKeysBatch[G, B, F, K, E],
+	decoder func(encoded *E) (decoded D, e error),
+	filterDecoded func(decoded *D, filter *F) (keep bool),
+) GetByKeysBatch[G, B, F, K, D] {
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		keys []K,
+		filter *F,
+		found func(key K, val *D) error,
+	) error {
+		return getEncoded(ctx, con, bucket, keys, filter, func(key K, encoded *E) error {
+			decoded, e := decoder(encoded)
+			if nil != e {
+				return e
+			}
+			var keep bool = filterDecoded(&decoded, filter)
+			if !keep {
+				return nil
+			}
+			return found(key, &decoded)
+		})
+	}
+}
+
+// batchSlots keeps items of a batch in key order.
+type batchSlots[K comparable, V any] struct {
+	positions map[K][]int
+	vals      []V
+	got       []bool
+}
+
+func batchSlotsNew[K comparable, V any](batchSize int) batchSlots[K, V] {
+	return batchSlots[K, V]{
+		positions: make(map[K][]int, batchSize),
+		vals:      make([]V, batchSize),
+		got:       make([]bool, batchSize),
+	}
+}
+
+func (s *batchSlots[K, V]) reset(keys []K) {
+	for key := range s.positions {
+		delete(s.positions, key)
+	}
+	for ix, key := range keys {
+		s.positions[key] = append(s.positions[key], ix)
+		s.got[ix] = false
+	}
+}
+
+func (s *batchSlots[K, V]) found(key K, val *V) error {
+	for _, ix := range s.positions[key] {
+		s.vals[ix] = *val
+		s.got[ix] = true
+	}
+	return nil
+}
+
+// each passes got items in key order.
+func (s *batchSlots[K, V]) each(
+	size int,
+	consume func(val *V) (stop bool, e error),
+) (stop bool, e error) {
+	var zero V
+	for ix := 0; ix < size; ix++ {
+		if !s.got[ix] {
+			continue
+		}
+		stop, e = consume(&s.vals[ix])
+		s.vals[ix] = zero
+		if nil != e || stop {
+			return true, e
+		}
+	}
+	return false, nil
+}
+
+// batchRun gets items batch by batch and passes them in key order.
+func batchRun[K comparable, V any](
+	ctx context.Context,
+	keys []K,
+	batchSize int,
+	getBatch func(batch []K, found func(key K, val *V) error) error,
+	consume func(val *V) (stop bool, e error),
+) error {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	var slots batchSlots[K, V] = batchSlotsNew[K, V](batchSize)
+	var cc cancelCheck = cancelCheckNew(ctx)
+	for lbi := 0; lbi < len(keys); lbi += batchSize {
+		e := cc.check()
+		if nil != e {
+			return e
+		}
+
+		var ube int = lbi + batchSize
+		if len(keys) < ube {
+			ube = len(keys)
+		}
+		var batch []K = keys[lbi:ube]
+		slots.reset(batch)
+
+		e = getBatch(batch, slots.found)
+		if nil != e {
+			return e
+		}
+
+		stop, e := slots.each(len(batch), consume)
+		if nil != e {
+			return e
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// GetByKeysNewBatch creates a closure like GetByKeysNew which gets items batch by batch.
+//
+// Items are passed to the consumer in key order.
+//
+// The slots of a batch are created for each call;
+// the closure is safe for concurrent use if getKeys and getBatch are.
+//
+// # Arguments
+//   - getKeys: Gets keys for items.
+//   - getBatch: Gets items by keys.
+//   - batchSize: Max number of keys of a batch(at least 1).
+func GetByKeysNewBatch[G any, K comparable, F, B, V any](
+	getKeys GetKeys[G, B, F, K],
+	getBatch GetByKeysBatch[G, B, F, K, V],
+	batchSize int,
+) Got2Consumer[G, K, F, B, V] {
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		filter *F,
+		buf *V,
+		consumer func(val *V, filter *F) (stop bool, e error),
+	) error {
+		keys, e := getKeys(ctx, con, bucket, filter)
+		if nil != e {
+			return e
+		}
+		return batchRun(
+			ctx,
+			keys,
+			batchSize,
+			func(batch []K, found func(K, *V) error) error {
+				return getBatch(ctx, con, bucket, batch, filter, found)
+			},
+			func(val *V) (stop bool, e error) {
+				*buf = *val
+				return consumer(buf, filter)
+			},
+		)
+	}
+}
+
+// GetByKeysNewUnnestedBatch creates a closure like GetByKeysNewUnnested
+// which gets packed items batch by batch.
+//
+// Unnested items are passed to the consumer in key order.
+//
+// Like GetByKeysNewBatch, each call has its own slots(buf is the caller's).
+//
+// # Arguments
+//   - getBatch: Gets packed items by keys(any order).
+//   - batchSize: Max number of keys of a batch(at least 1).
+//   - unnest: Gets unnested items from a packed item.
+//   - filterPacked: Checks if a packed item must be used or not.
+//   - filterUnpacked: Check if an unpacked item must be used or not.
+//   - consumeUnpacked: Uses an unpacked item.
+func GetByKeysNewUnnestedBatch[G any, K comparable, P, F, U any](
+	getBatch func(ctx context.Context, con G, keys []K, found func(key K, packed *P) error) error,
+	batchSize int,
+	unnest Unnest[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	filterUnpacked func(unpacked *U, filter *F) (keep bool),
+	consumeUnpacked IterConsumer[U],
+) func(ctx context.Context, keys []K, get G, buf *P, filter *F) error {
+	return func(ctx context.Context, keys []K, con G, buf *P, filter *F) error {
+		return batchRun(
+			ctx,
+			keys,
+			batchSize,
+			func(batch []K, found func(K, *P) error) error {
+				return getBatch(ctx, con, batch, found)
+			},
+			func(packed *P) (stop bool, e error) {
+				*buf = *packed
+				var keepPacked bool = filterPacked(buf, filter)
+				if !keepPacked {
+					return false, nil
+				}
+				unnested, e := unnest(buf)
+				if nil != e {
+					return true, e
+				}
+				return IterConsumerFilterMany(consumeUnpacked, unnested, filterUnpacked, filter)
+			},
+		)
+	}
+}
@@ -0,0 +1,275 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	var store map[int]string = map[int]string{
+		1: "1", 2: "2", 3: "3", 4: "4", 5: "5", 6: "6", 7: "7",
+	}
+
+	// passes items in reverse order.
+	var batchSizes []int
+	var getBatch GetByKeysBatch[map[int]string, string, int, int, string] = func(
+		_ context.Context,
+		con map[int]string,
+		_ *string,
+		keys []int,
+		_ *int,
+		found func(key int, val *string) error,
+	) error {
+		batchSizes = append(batchSizes, len(keys))
+		for ix := len(keys) - 1; 0 <= ix; ix-- {
+			val, ok := con[keys[ix]]
+			if !ok {
+				continue
+			}
+			e := found(keys[ix], &val)
+			if nil != e {
+				return e
+			}
+		}
+		return nil
+	}
+
+	var getKeys GetKeys[map[int]string, string, int, int] = func(
+		_ context.Context,
+		_ map[int]string,
+		_ *string,
+		_ *int,
+	) ([]int, error) {
+		return []int{7, 3, 42, 5, 1, 3, 2}, nil
+	}
+
+	t.Run("GetByKeysNewBatch", func(t *testing.T) {
+		t.Run("key order", func(t *testing.T) {
+			batchSizes = nil
+			var vals []string
+			f := GetByKeysNewBatch(getKeys, getBatch, 3)
+			var buf string
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				val *string,
+				_ *int,
+			) (stop bool, e error) {
+				vals = append(vals, *val)
+				return false, nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("3 batches", assertEq(len(batchSizes), 3))
+			t.Run("last batch", assertEq(batchSizes[2], 1))
+			t.Run("6 items", assertEq(len(vals), 6))
+			t.Run("order", assertEq(
+				vals[0]+vals[1]+vals[2]+vals[3]+vals[4]+vals[5],
+				"735132",
+			))
+		})
+
+		t.Run("stop", func(t *testing.T) {
+			batchSizes = nil
+			var vals []string
+			f := GetByKeysNewBatch(getKeys, getBatch, 2)
+			var buf string
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				val *string,
+				_ *int,
+			) (stop bool, e error) {
+				vals = append(vals, *val)
+				return 2 == len(vals), nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("single batch", assertEq(len(batchSizes), 1))
+			t.Run("2 items", assertEq(len(vals), 2))
+		})
+
+		t.Run("decoded", func(t *testing.T) {
+			var vals []int
+			f := GetByKeysNewBatch(
+				getKeys,
+				GetByKeysBatchDecodedNew(
+					getBatch,
+					func(encoded *string) (int, error) { return strconv.Atoi(*encoded) },
+					func(decoded *int, filter *int) bool { return *filter <= *decoded },
+				),
+				4,
+			)
+			var buf int
+			var filter int = 3
+			e := f(context.Background(), store, nil, &filter, &buf, func(
+				val *int,
+				_ *int,
+			) (stop bool, e error) {
+				vals = append(vals, *val)
+				return false, nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("4 items", assertEq(len(vals), 4))
+			t.Run("first", assertEq(vals[0], 7))
+			t.Run("last", assertEq(vals[3], 3))
+		})
+
+		t.Run("decode error", func(t *testing.T) {
+			f := GetByKeysNewBatch(
+				getKeys,
+				GetByKeysBatchDecodedNew(
+					getBatch,
+					func(_ *string) (int, error) { return 0, testErrorInvalidKey },
+					func(_ *int, _ *int) bool { return true },
+				),
+				4,
+			)
+			var buf int
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				_ *int,
+				_ *int,
+			) (stop bool, e error) {
+				return false, nil
+			})
+			t.Run("error", assertEq(errors.Is(e, testErrorInvalidKey), true))
+		})
+
+		t.Run("from key", func(t *testing.T) {
+			var vals []string
+			f := GetByKeysNewBatch(
+				getKeys,
+				GetByKeysBatchFromKey(GetByKey[map[int]string, string, int, int, string](func(
+					_ context.Context,
+					con map[int]string,
+					_ *string,
+					key int,
+					val *string,
+					_ *int,
+				) (got bool, e error) {
+					*val, got = con[key]
+					return got, nil
+				})),
+				16,
+			)
+			var buf string
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				val *string,
+				_ *int,
+			) (stop bool, e error) {
+				vals = append(vals, *val)
+				return false, nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("6 items", assertEq(len(vals), 6))
+			t.Run("first", assertEq(vals[0], "7"))
+		})
+
+		t.Run("from key reusing buffer", func(t *testing.T) {
+			var vals []string
+			f := GetByKeysNewBatch(
+				GetKeys[map[int]string, string, int, int](func(
+					_ context.Context,
+					_ map[int]string,
+					_ *string,
+					_ *int,
+				) ([]int, error) {
+					return []int{1, 2, 3}, nil
+				}),
+				GetByKeysBatchFromKey(GetByKey[map[int]string, string, int, int, []byte](func(
+					_ context.Context,
+					con map[int]string,
+					_ *string,
+					key int,
+					val *[]byte,
+					_ *int,
+				) (got bool, e error) {
+					s, got := con[key]
+					*val = append((*val)[:0], s...)
+					return got, nil
+				})),
+				3,
+			)
+			var buf []byte
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				val *[]byte,
+				_ *int,
+			) (stop bool, e error) {
+				vals = append(vals, string(*val))
+				return false, nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("3 items", assertEq(len(vals), 3))
+			t.Run("items", assertEq(vals[0]+vals[1]+vals[2], "123"))
+		})
+	})
+
+	t.Run("GetByKeysBatchFromKey cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var fetched int = 0
+		f := GetByKeysBatchFromKey(GetByKey[uint8, string, uint8, int, int](func(
+			_ context.Context,
+			_ uint8,
+			_ *string,
+			key int,
+			val *int,
+			_ *uint8,
+		) (bool, error) {
+			fetched += 1
+			*val = key
+			return true, nil
+		}))
+		e := f(ctx, 0, nil, []int{0, 1, 2}, nil, func(_ int, _ *int) error { return nil })
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("no fetch", assertEq(fetched, 0))
+	})
+
+	t.Run("GetByKeysNewUnnestedBatch", func(t *testing.T) {
+		t.Parallel()
+
+		var packedStore map[int][]int = map[int][]int{
+			1: {10, 11},
+			2: {20, 21},
+			3: {30, 31},
+		}
+
+		var unnested []int
+		f := GetByKeysNewUnnestedBatch(
+			func(
+				_ context.Context,
+				con map[int][]int,
+				keys []int,
+				found func(key int, packed *[]int) error,
+			) error {
+				for ix := len(keys) - 1; 0 <= ix; ix-- {
+					packed, ok := con[keys[ix]]
+					if !ok {
+						continue
+					}
+					e := found(keys[ix], &packed)
+					if nil != e {
+						return e
+					}
+				}
+				return nil
+			},
+			2,
+			func(packed *[]int) ([]int, error) { return *packed, nil },
+			func(packed *[]int, filter *int) bool { return (*packed)[0] != *filter },
+			func(unpacked *int, _ *int) bool { return 1 == *unpacked%10 },
+			func(unpacked *int) (stop bool, e error) {
+				unnested = append(unnested, *unpacked)
+				return false, nil
+			},
+		)
+
+		var buf []int
+		var filter int = 20
+		e := f(context.Background(), []int{3, 2, 1}, packedStore, &buf, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("2 items", assertEq(len(unnested), 2))
+		t.Run("first", assertEq(unnested[0], 31))
+		t.Run("last", assertEq(unnested[1], 11))
+	})
+}
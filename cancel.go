@@ -0,0 +1,65 @@
+package local
+
+import (
+	"context"
+)
+
+// CancelCheckIntervalDefault is the default number of items between cancellation checks.
+const CancelCheckIntervalDefault int = 1
+
+type cancelCheckIntervalKey struct{}
+
+// WithCancelCheckInterval creates a new context which decides how often loops check it.
+//
+// Iteration loops check the context before the first item and after every interval items;
+// a cancelled context stops a loop which returns ctx.Err().
+//
+// # Arguments
+//   - parent: The parent context.
+//   - every: The number of items between checks(at least 1).
+func WithCancelCheckInterval(parent context.Context, every int) context.Context {
+	if every < 1 {
+		every = 1
+	}
+	return context.WithValue(parent, cancelCheckIntervalKey{}, every)
+}
+
+// CancelCheckInterval returns the number of items between cancellation checks.
+func CancelCheckInterval(ctx context.Context) int {
+	every, ok := ctx.Value(cancelCheckIntervalKey{}).(int)
+	if !ok {
+		return CancelCheckIntervalDefault
+	}
+	return every
+}
+
+type cancelCheck struct {
+	ctx   context.Context
+	every int
+	count int
+}
+
+func cancelCheckNew(ctx context.Context) cancelCheck {
+	var every int = 0
+	if nil != ctx.Done() {
+		every = CancelCheckInterval(ctx)
+	}
+	return cancelCheck{
+		ctx:   ctx,
+		every: every,
+		count: 0,
+	}
+}
+
+// check returns ctx.Err() if the context must be checked(never cancelled contexts are ignored).
+func (c *cancelCheck) check() error {
+	if 0 == c.every {
+		return nil
+	}
+	var ix int = c.count
+	c.count += 1
+	if 0 != ix%c.every {
+		return nil
+	}
+	return c.ctx.Err()
+}
@@ -0,0 +1,231 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCancel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CancelCheckInterval", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("default", assertEq(
+			CancelCheckInterval(context.Background()),
+			CancelCheckIntervalDefault,
+		))
+		t.Run("configured", assertEq(
+			CancelCheckInterval(WithCancelCheckInterval(context.Background(), 8)),
+			8,
+		))
+		t.Run("at least 1", assertEq(
+			CancelCheckInterval(WithCancelCheckInterval(context.Background(), 0)),
+			1,
+		))
+	})
+
+	// an endless iterator: only cancellation(or the consumer) can stop a scan.
+	var iterNext func(iter *int) bool = func(_ *int) bool { return true }
+	var iterGet func(iter *int, val *int) error = func(iter *int, val *int) error {
+		*val = *iter
+		*iter += 1
+		return nil
+	}
+	var iterErr func(iter *int) error = func(_ *int) error { return nil }
+
+	cancelled := func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx
+	}
+
+	t.Run("Iter2UnpackedNew", func(t *testing.T) {
+		t.Parallel()
+
+		f := Iter2UnpackedNew(
+			func(packed *int) (int, error) { return *packed, nil },
+			iterNext,
+			iterGet,
+			iterErr,
+		)
+		var iter int = 0
+		_, e := f(cancelled(), &iter)
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("no items read", assertEq(iter, 0))
+	})
+
+	t.Run("Iter2ConsumerNewFiltered", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var consumed int = 0
+		f := Iter2ConsumerNewFiltered(
+			iterNext,
+			iterGet,
+			iterErr,
+			func(_ *uint8, _ *int) bool { return true },
+			func(_ *int) (stop bool, e error) {
+				consumed += 1
+				if 3 == consumed {
+					cancel()
+				}
+				return false, nil
+			},
+		)
+		var iter int = 0
+		var buf int
+		e := f(ctx, &iter, &buf, nil)
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("stopped promptly", assertEq(consumed, 3))
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var consumed int = 0
+		f := Iter2ConsumerNewUnpacked(
+			iterNext,
+			iterGet,
+			iterErr,
+			func(packed *int) (int, error) { return *packed, nil },
+			func(_ *int, _ *uint8) bool { return true },
+			func(_ *int, _ *uint8) bool { return true },
+			func(_ *int) (stop bool, e error) {
+				consumed += 1
+				if 1 == consumed {
+					cancel()
+				}
+				return false, nil
+			},
+		)
+		var iter int = 0
+		var buf int
+		e := f(WithCancelCheckInterval(ctx, 4), &iter, &buf, nil)
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("checked after 4 items", assertEq(consumed, 4))
+	})
+
+	t.Run("Iter2ConsumerNewUnnested", func(t *testing.T) {
+		t.Parallel()
+
+		var consumed int = 0
+		f := Iter2ConsumerNewUnnested(
+			iterNext,
+			iterGet,
+			iterErr,
+			func(packed *int) ([]int, error) { return []int{*packed}, nil },
+			func(_ *int, _ *uint8) bool { return true },
+			func(_ *int, _ *uint8) bool { return true },
+			func(_ *int) (stop bool, e error) {
+				consumed += 1
+				return false, nil
+			},
+		)
+		var iter int = 0
+		var buf int
+		e := f(cancelled(), &iter, &buf, nil)
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("no items", assertEq(consumed, 0))
+	})
+
+	t.Run("GetByKeysNew", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var fetched int = 0
+		f := GetByKeysNew(
+			GetKeys[uint8, string, uint8, int](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				_ *uint8,
+			) ([]int, error) {
+				return []int{0, 1, 2, 3, 4, 5, 6, 7}, nil
+			}),
+			GetByKey[uint8, string, uint8, int, int](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				key int,
+				val *int,
+				_ *uint8,
+			) (bool, error) {
+				fetched += 1
+				*val = key
+				return true, nil
+			}),
+		)
+		var buf int
+		e := f(ctx, 0, nil, nil, &buf, func(val *int, _ *uint8) (stop bool, e error) {
+			if 1 == *val {
+				cancel()
+			}
+			return false, nil
+		})
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("2 fetched", assertEq(fetched, 2))
+	})
+
+	t.Run("GetByKeysNewUnnested", func(t *testing.T) {
+		t.Parallel()
+
+		var fetched int = 0
+		f := GetByKeysNewUnnested(
+			func(_ context.Context, _ uint8, key int, packed *int) (bool, error) {
+				fetched += 1
+				*packed = key
+				return true, nil
+			},
+			func(packed *int) ([]int, error) { return []int{*packed}, nil },
+			func(_ *int, _ *uint8) bool { return true },
+			func(_ *int, _ *uint8) bool { return true },
+			func(_ *int) (stop bool, e error) { return false, nil },
+		)
+		var buf int
+		e := f(cancelled(), []int{0, 1, 2}, 0, &buf, nil)
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("no fetch", assertEq(fetched, 0))
+	})
+
+	t.Run("PipelineIterNew", func(t *testing.T) {
+		t.Parallel()
+
+		var consumed int = 0
+		f := PipelineIterNew(
+			iterNext,
+			iterGet,
+			iterErr,
+			PipelineNew[int, uint8](),
+			func(_ *int, _ *uint8) (stop bool, e error) {
+				consumed += 1
+				return false, nil
+			},
+		)
+		var iter int = 0
+		e := f(cancelled(), &iter, nil)
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("no items", assertEq(consumed, 0))
+	})
+
+	t.Run("background", func(t *testing.T) {
+		t.Parallel()
+
+		var consumed int = 0
+		f := PipelineSliceNew(
+			PipelineNew[int, uint8](),
+			func(_ *int, _ *uint8) (stop bool, e error) {
+				consumed += 1
+				return false, nil
+			},
+		)
+		e := f(context.Background(), []int{1, 2, 3}, nil)
+		t.Run("no error", assertNil(e))
+		t.Run("all items", assertEq(consumed, 3))
+	})
+}
@@ -0,0 +1,112 @@
+package local
+
+import (
+	"fmt"
+)
+
+// LevelError describes an error from a level of an UnnestChain.
+type LevelError struct {
+	level int
+	err   error
+}
+
+// Level returns the level(0: the outermost unnest) of the error.
+func (l LevelError) Level() int { return l.level }
+
+// Unwrap returns the underlying error.
+func (l LevelError) Unwrap() error { return l.err }
+
+func (l LevelError) Error() string { return fmt.Sprintf("level=%v: %v", l.level, l.err) }
+
+// UnnestChain unnests packs of packs(arbitrary depth).
+type UnnestChain[P, U, F any] struct {
+	depth int
+	run   func(packed *P, filter *F, each func(unnested *U) (stop bool, e error)) (bool, error)
+}
+
+func unnestLevel[P, U, F any](
+	level int,
+	unnest Unnest[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	packed *P,
+	filter *F,
+	each func(unnested *U) (stop bool, e error),
+) (stop bool, e error) {
+	var keep bool = filterPacked(packed, filter)
+	if !keep {
+		return false, nil
+	}
+	unnested, e := unnest(packed)
+	if nil != e {
+		return true, LevelError{level: level, err: e}
+	}
+	for ix := range unnested {
+		stop, e = each(&unnested[ix])
+		if nil != e || stop {
+			return true, e
+		}
+	}
+	return false, nil
+}
+
+// UnnestChainNew creates an UnnestChain which has a level.
+//
+// # Arguments
+//   - unnest: Gets unnested items from a packed item.
+//   - filterPacked: Checks if a packed item must be unnested or not.
+func UnnestChainNew[P, U, F any](
+	unnest Unnest[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+) UnnestChain[P, U, F] {
+	return UnnestChain[P, U, F]{
+		depth: 1,
+		run: func(packed *P, filter *F, each func(*U) (bool, error)) (bool, error) {
+			return unnestLevel(0, unnest, filterPacked, packed, filter, each)
+		},
+	}
+}
+
+// UnnestChainThen creates a new UnnestChain which unnests items of a chain.
+//
+// # Arguments
+//   - c: The outer levels.
+//   - unnest: Gets unnested items from an item of the outer levels.
+//   - filterMid: Checks if an item of the outer levels must be unnested or not.
+func UnnestChainThen[P, M, U, F any](
+	c UnnestChain[P, M, F],
+	unnest Unnest[M, U],
+	filterMid func(mid *M, filter *F) (keep bool),
+) UnnestChain[P, U, F] {
+	var level int = c.depth
+	return UnnestChain[P, U, F]{
+		depth: c.depth + 1,
+		run: func(packed *P, filter *F, each func(*U) (bool, error)) (bool, error) {
+			return c.run(packed, filter, func(mid *M) (stop bool, e error) {
+				return unnestLevel(level, unnest, filterMid, mid, filter, each)
+			})
+		},
+	}
+}
+
+// Depth returns the number of levels.
+func (c UnnestChain[P, U, F]) Depth() int { return c.depth }
+
+// ToFiltered creates an UnnestFiltered which uses all levels.
+//
+// Errors from unnest functions are wrapped by LevelError;
+// errors from the callback are returned as is.
+// Intermediate items are local to a call so that the UnnestFiltered is safe
+// for concurrent use if the unnest and filter functions of all levels are.
+func (c UnnestChain[P, U, F]) ToFiltered() UnnestFiltered[P, U, F] {
+	return func(
+		packed *P,
+		filter *F,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		return c.run(packed, filter, func(item *U) (stop bool, e error) {
+			*buf = *item
+			return each(buf)
+		})
+	}
+}
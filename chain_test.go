@@ -0,0 +1,111 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testChainDay struct{ minutes []testChainMinute }
+
+type testChainMinute struct {
+	minute  int32
+	seconds []testChainSecond
+}
+
+type testChainSecond struct {
+	second uint8
+	events []int64
+}
+
+type testChainFilter struct {
+	minute int32
+	second uint8
+	event  int64
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	var day testChainDay = testChainDay{
+		minutes: []testChainMinute{
+			{minute: 1, seconds: []testChainSecond{
+				{second: 0, events: []int64{1, 2, 3}},
+				{second: 1, events: []int64{4}},
+			}},
+			{minute: 2, seconds: []testChainSecond{
+				{second: 0, events: []int64{5, 6}},
+				{second: 1, events: []int64{7, 8, 9}},
+			}},
+		},
+	}
+
+	var chain UnnestChain[testChainDay, int64, testChainFilter] = UnnestChainThen(
+		UnnestChainThen(
+			UnnestChainNew(
+				Unnest[testChainDay, testChainMinute](func(
+					d *testChainDay,
+				) ([]testChainMinute, error) {
+					return d.minutes, nil
+				}),
+				func(d *testChainDay, f *testChainFilter) bool { return true },
+			),
+			Unnest[testChainMinute, testChainSecond](func(
+				m *testChainMinute,
+			) ([]testChainSecond, error) {
+				if m.minute < 0 {
+					return nil, testErrorInvalidKey
+				}
+				return m.seconds, nil
+			}),
+			func(m *testChainMinute, f *testChainFilter) bool { return f.minute <= m.minute },
+		),
+		Unnest[testChainSecond, int64](func(s *testChainSecond) ([]int64, error) {
+			return s.events, nil
+		}),
+		func(s *testChainSecond, f *testChainFilter) bool { return f.second <= s.second },
+	)
+
+	t.Run("depth", assertEq(chain.Depth(), 3))
+
+	var events []int64
+	run := PipelineSliceNew(
+		PipeUnnestFiltered(PipelineNew[testChainDay, testChainFilter](), chain.ToFiltered()).
+			Filter(func(ev *int64, f *testChainFilter) bool { return f.event <= *ev }),
+		func(ev *int64, _ *testChainFilter) (stop bool, e error) {
+			events = append(events, *ev)
+			return 3 == len(events), nil
+		},
+	)
+
+	t.Run("coarse filters", func(t *testing.T) {
+		events = nil
+		var filter testChainFilter = testChainFilter{minute: 2, second: 1, event: 8}
+		e := run(context.Background(), []testChainDay{day}, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("2 events", assertEq(len(events), 2))
+		t.Run("first", assertEq(events[0], 8))
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		events = nil
+		var filter testChainFilter
+		e := run(context.Background(), []testChainDay{day, day}, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("3 events", assertEq(len(events), 3))
+	})
+
+	t.Run("level error", func(t *testing.T) {
+		events = nil
+		var broken testChainDay = testChainDay{
+			minutes: []testChainMinute{{minute: -1}},
+		}
+		var filter testChainFilter = testChainFilter{minute: -2}
+		e := run(context.Background(), []testChainDay{broken}, &filter)
+
+		var le LevelError
+		t.Run("level error", assertEq(errors.As(e, &le), true))
+		t.Run("level 1", assertEq(le.Level(), 1))
+		t.Run("cause", assertEq(errors.Is(e, testErrorInvalidKey), true))
+	})
+}
@@ -0,0 +1,154 @@
+package codec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// ErrAuthentication is returned when a sealed value can not be opened.
+var ErrAuthentication = errors.New("authentication failed")
+
+// KeyProvider must return an AES key(16, 24 or 32 bytes) for a key ID.
+type KeyProvider func(keyId uint32) (key []byte, e error)
+
+// Sealed contains an encrypted value and its location.
+//
+// The bucket name and the key are bound to the value as associated data.
+type Sealed struct {
+	Bucket local.Bucket
+	Key    []byte
+	Val    []byte
+}
+
+// AssociatedData creates associated data from a bucket and a key.
+func AssociatedData(bucket local.Bucket, key []byte) []byte {
+	var name string = bucket.AsString()
+	var ad []byte = make([]byte, 0, binary.MaxVarintLen64+len(name)+len(key))
+	ad = binary.AppendUvarint(ad, uint64(len(name)))
+	ad = append(ad, name...)
+	return append(ad, key...)
+}
+
+// aeadCache caches a cipher.AEAD for each key ID.
+//
+// The lock is not held while the KeyProvider runs
+// so that a slow provider does not block cached keys.
+type aeadCache struct {
+	mu    sync.Mutex
+	keys  KeyProvider
+	cache map[uint32]cipher.AEAD
+}
+
+func (c *aeadCache) get(keyId uint32) (cipher.AEAD, error) {
+	c.mu.Lock()
+	cached, found := c.cache[keyId]
+	c.mu.Unlock()
+	if found {
+		return cached, nil
+	}
+	key, e := c.keys(keyId)
+	if nil != e {
+		return nil, e
+	}
+	block, e := aes.NewCipher(key)
+	if nil != e {
+		return nil, e
+	}
+	gcm, e := cipher.NewGCM(block)
+	if nil != e {
+		return nil, e
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, found = c.cache[keyId]
+	if found {
+		return cached, nil
+	}
+	c.cache[keyId] = gcm
+	return gcm, nil
+}
+
+func aeadCacheNew(keys KeyProvider) *aeadCache {
+	return &aeadCache{
+		keys:  keys,
+		cache: map[uint32]cipher.AEAD{},
+	}
+}
+
+// SealNew creates a closure which encrypts a value using AES-GCM.
+//
+// Layout: key ID(4 bytes, big endian) | nonce | ciphertext + tag.
+//
+// The closure is safe for concurrent use if keys and random are;
+// keys may be called more than once for the same key ID.
+//
+// # Arguments
+//   - keys: Gets an AES key by a key ID.
+//   - keyId: The ID of the key to encrypt values(the latest key).
+//   - random: The source of nonces(e.g, crypto/rand.Reader).
+func SealNew(
+	keys KeyProvider,
+	keyId uint32,
+	random io.Reader,
+) func(bucket local.Bucket, key []byte, plain []byte) (sealed []byte, e error) {
+	var cache *aeadCache = aeadCacheNew(keys)
+	return func(bucket local.Bucket, key []byte, plain []byte) (sealed []byte, e error) {
+		gcm, e := cache.get(keyId)
+		if nil != e {
+			return nil, e
+		}
+		var size int = 4 + gcm.NonceSize() + len(plain) + gcm.Overhead()
+		sealed = make([]byte, 4+gcm.NonceSize(), size)
+		binary.BigEndian.PutUint32(sealed[0:4], keyId)
+		var nonce []byte = sealed[4:]
+		_, e = io.ReadFull(random, nonce)
+		if nil != e {
+			return nil, e
+		}
+		return gcm.Seal(sealed, nonce, plain, AssociatedData(bucket, key)), nil
+	}
+}
+
+// OpenNew creates a Decode which decrypts a sealed value before decoding.
+//
+// Keys are looked up by the key ID of each value so that rotated keys can be used.
+// The ciphers are cached by a lock; the Decode is safe for concurrent use if keys and inner are.
+//
+// # Arguments
+//   - keys: Gets an AES key by a key ID.
+//   - inner: Decodes a decrypted value.
+func OpenNew[D any](keys KeyProvider, inner local.Decode[[]byte, D]) local.Decode[Sealed, D] {
+	var cache *aeadCache = aeadCacheNew(keys)
+	return func(encoded Sealed) (decoded D, e error) {
+		if len(encoded.Val) < 4 {
+			return decoded, ErrInvalidSize
+		}
+		var keyId uint32 = binary.BigEndian.Uint32(encoded.Val[0:4])
+		gcm, e := cache.get(keyId)
+		if nil != e {
+			return decoded, e
+		}
+		var rest []byte = encoded.Val[4:]
+		if len(rest) < gcm.NonceSize()+gcm.Overhead() {
+			return decoded, ErrInvalidSize
+		}
+		var nonce []byte = rest[:gcm.NonceSize()]
+		var ciphertext []byte = rest[gcm.NonceSize():]
+		plain, e := gcm.Open(
+			nil,
+			nonce,
+			ciphertext,
+			AssociatedData(encoded.Bucket, encoded.Key),
+		)
+		if nil != e {
+			return decoded, ErrAuthentication
+		}
+		return inner(plain)
+	}
+}
@@ -0,0 +1,133 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+var testErrUnknownKey = errors.New("unknown key")
+
+func testKeys(keyId uint32) ([]byte, error) {
+	switch keyId {
+	case 1:
+		return bytes.Repeat([]byte{0x01}, 16), nil
+	case 2:
+		return bytes.Repeat([]byte{0x02}, 32), nil
+	default:
+		return nil, testErrUnknownKey
+	}
+}
+
+func TestAead(t *testing.T) {
+	t.Parallel()
+
+	var decode local.Decode[Sealed, testRow] = OpenNew(testKeys, JsonNew[testRow]())
+	var bucket local.Bucket = local.BucketNew("tenant_a")
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		seal := SealNew(testKeys, 1, rand.Reader)
+		sealed, e := seal(bucket, []byte("k1"), []byte(`{"minute":42}`))
+		t.Run("sealed", assertNil(e))
+
+		decoded, e := decode(Sealed{Bucket: bucket, Key: []byte("k1"), Val: sealed})
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 42))
+	})
+
+	t.Run("rotated keys", func(t *testing.T) {
+		t.Parallel()
+
+		old, _ := SealNew(testKeys, 1, rand.Reader)(bucket, []byte("k"), []byte(`{"minute":1}`))
+		cur, _ := SealNew(testKeys, 2, rand.Reader)(bucket, []byte("k"), []byte(`{"minute":2}`))
+
+		decodedOld, e := decode(Sealed{Bucket: bucket, Key: []byte("k"), Val: old})
+		t.Run("old key", assertNil(e))
+		t.Run("old minute", assertEq(decodedOld.Minute, 1))
+
+		decodedCur, e := decode(Sealed{Bucket: bucket, Key: []byte("k"), Val: cur})
+		t.Run("current key", assertNil(e))
+		t.Run("current minute", assertEq(decodedCur.Minute, 2))
+	})
+
+	t.Run("associated data", func(t *testing.T) {
+		t.Parallel()
+
+		sealed, _ := SealNew(testKeys, 1, rand.Reader)(bucket, []byte("k1"), []byte(`{}`))
+
+		_, e := decode(Sealed{Bucket: bucket, Key: []byte("k2"), Val: sealed})
+		t.Run("other key", assertEq(errors.Is(e, ErrAuthentication), true))
+
+		_, e = decode(Sealed{Bucket: local.BucketNew("tenant_b"), Key: []byte("k1"), Val: sealed})
+		t.Run("other bucket", assertEq(errors.Is(e, ErrAuthentication), true))
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		t.Parallel()
+
+		sealed, _ := SealNew(testKeys, 1, rand.Reader)(bucket, nil, []byte(`{}`))
+		sealed[len(sealed)-1] ^= 0x01
+		_, e := decode(Sealed{Bucket: bucket, Val: sealed})
+		t.Run("error", assertEq(errors.Is(e, ErrAuthentication), true))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		t.Parallel()
+
+		_, e := decode(Sealed{Bucket: bucket, Val: []byte{0, 0, 0, 9}})
+		t.Run("error", assertEq(errors.Is(e, testErrUnknownKey), true))
+
+		_, e = SealNew(testKeys, 9, rand.Reader)(bucket, nil, nil)
+		t.Run("seal error", assertEq(errors.Is(e, testErrUnknownKey), true))
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		t.Parallel()
+
+		_, e := decode(Sealed{Bucket: bucket, Val: []byte{0, 0, 0, 1, 0}})
+		t.Run("error", assertEq(errors.Is(e, ErrInvalidSize), true))
+	})
+}
+
+func TestAeadSlowKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	var entered chan struct{} = make(chan struct{})
+	var release chan struct{} = make(chan struct{})
+	var slow KeyProvider = func(keyId uint32) ([]byte, error) {
+		if 2 == keyId {
+			close(entered)
+			<-release
+		}
+		return testKeys(keyId)
+	}
+	var decode local.Decode[Sealed, testRow] = OpenNew(slow, JsonNew[testRow]())
+	var bucket local.Bucket = local.BucketNew("tenant_a")
+
+	fast, _ := SealNew(testKeys, 1, rand.Reader)(bucket, nil, []byte(`{"minute":1}`))
+	blocked, _ := SealNew(testKeys, 2, rand.Reader)(bucket, nil, []byte(`{"minute":2}`))
+
+	_, e := decode(Sealed{Bucket: bucket, Val: fast})
+	t.Run("cached", assertNil(e))
+
+	var done chan error = make(chan error, 1)
+	go func() {
+		_, e := decode(Sealed{Bucket: bucket, Val: blocked})
+		done <- e
+	}()
+	<-entered
+
+	for i := 0; i < 8; i++ {
+		decoded, e := decode(Sealed{Bucket: bucket, Val: fast})
+		t.Run("not blocked", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 1))
+	}
+
+	close(release)
+	t.Run("slow key", assertNil(<-done))
+}
@@ -0,0 +1,46 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// BinaryNew creates a Decode which decodes fixed-layout bytes into a typed value.
+//
+// The decoded type must be a fixed-size value accepted by binary.Read.
+//
+// The size of D is computed once and only read afterwards;
+// the Decode is safe for concurrent use.
+//
+// # Arguments
+//   - order: binary.BigEndian or binary.LittleEndian.
+func BinaryNew[D any](order binary.ByteOrder) local.Decode[[]byte, D] {
+	var empty D
+	var size int = binary.Size(&empty)
+	return func(encoded []byte) (decoded D, e error) {
+		if size < 0 {
+			return decoded, ErrUnsupportedType
+		}
+		if size != len(encoded) {
+			return decoded, ErrInvalidSize
+		}
+		e = binary.Read(bytes.NewReader(encoded), order, &decoded)
+		return
+	}
+}
+
+// BinaryEncodeNew creates an Encode which encodes a fixed-size value.
+//
+// Each value is written to its own buffer; the Encode is safe for concurrent use.
+//
+// # Arguments
+//   - order: binary.BigEndian or binary.LittleEndian.
+func BinaryEncodeNew[D any](order binary.ByteOrder) local.Encode[D, []byte] {
+	return func(decoded D) (encoded []byte, e error) {
+		var buf bytes.Buffer
+		e = binary.Write(&buf, order, &decoded)
+		return buf.Bytes(), e
+	}
+}
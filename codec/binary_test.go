@@ -0,0 +1,67 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+type testFixed struct {
+	Timestamp int64
+	Coarse    uint16
+	Val       [2]uint64
+}
+
+func TestBinary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("BinaryNew", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("big endian", func(t *testing.T) {
+			t.Parallel()
+
+			var decode local.Decode[[]byte, testFixed] = BinaryNew[testFixed](binary.BigEndian)
+
+			var encoded []byte = make([]byte, 26)
+			binary.BigEndian.PutUint64(encoded[0:8], 0x0123456789abcdef)
+			binary.BigEndian.PutUint16(encoded[8:10], 0x3776)
+			binary.BigEndian.PutUint64(encoded[10:18], 0x42)
+			binary.BigEndian.PutUint64(encoded[18:26], 0x43)
+
+			decoded, e := decode(encoded)
+			t.Run("no error", assertNil(e))
+			t.Run("timestamp", assertEq(decoded.Timestamp, 0x0123456789abcdef))
+			t.Run("coarse", assertEq(decoded.Coarse, 0x3776))
+			t.Run("val 0", assertEq(decoded.Val[0], 0x42))
+			t.Run("val 1", assertEq(decoded.Val[1], 0x43))
+		})
+
+		t.Run("little endian", func(t *testing.T) {
+			t.Parallel()
+
+			var decode local.Decode[[]byte, uint32] = BinaryNew[uint32](binary.LittleEndian)
+			decoded, e := decode([]byte{0x01, 0x02, 0x03, 0x04})
+			t.Run("no error", assertNil(e))
+			t.Run("value", assertEq(decoded, 0x04030201))
+		})
+
+		t.Run("invalid size", func(t *testing.T) {
+			t.Parallel()
+
+			var decode local.Decode[[]byte, testFixed] = BinaryNew[testFixed](binary.BigEndian)
+			_, e := decode(make([]byte, 25))
+			t.Run("error", assertEq(errors.Is(e, ErrInvalidSize), true))
+		})
+
+		t.Run("unsupported", func(t *testing.T) {
+			t.Parallel()
+
+			var decode local.Decode[[]byte, string] = BinaryNew[string](binary.BigEndian)
+			_, e := decode(nil)
+			t.Run("error", assertEq(errors.Is(e, ErrUnsupportedType), true))
+		})
+	})
+}
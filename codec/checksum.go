@@ -0,0 +1,243 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/bits"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// ErrCorrupted is returned(wrapped by ChecksumError) when a checksum does not match.
+var ErrCorrupted = errors.New("corrupted")
+
+// ChecksumError describes a checksum mismatch.
+type ChecksumError struct {
+	Expected uint64
+	Actual   uint64
+}
+
+func (c ChecksumError) Error() string {
+	return fmt.Sprintf("%v: expected=%x actual=%x", ErrCorrupted, c.Expected, c.Actual)
+}
+
+// Is returns true for ErrCorrupted.
+func (c ChecksumError) Is(target error) bool { return ErrCorrupted == target }
+
+// Checksum contains a checksum function and its stored size.
+type Checksum struct {
+	size int
+	sum  func(data []byte) uint64
+}
+
+// ChecksumNew creates a Checksum.
+//
+// A 4 bytes checksum uses the lower 32 bits of the sum.
+//
+// # Arguments
+//   - size: The number of bytes(big endian) to store a checksum(4 or 8; 8 if greater than 4).
+//   - sum: Computes a checksum.
+func ChecksumNew(size int, sum func(data []byte) uint64) Checksum {
+	if size <= 4 {
+		size = 4
+	} else {
+		size = 8
+	}
+	return Checksum{
+		size: size,
+		sum:  sum,
+	}
+}
+
+var crc32cTable *crc32.Table = crc32.MakeTable(crc32.Castagnoli)
+
+// Crc32c computes CRC32-C(Castagnoli).
+func Crc32c(data []byte) uint64 { return uint64(crc32.Checksum(data, crc32cTable)) }
+
+var (
+	xxhPrime1 uint64 = 11400714785074694791
+	xxhPrime2 uint64 = 14029467366897019727
+	xxhPrime3 uint64 = 1609587929392839161
+	xxhPrime4 uint64 = 9650029242287828579
+	xxhPrime5 uint64 = 2870177450012600261
+)
+
+func xxhRound(acc, input uint64) uint64 {
+	acc += input * xxhPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxhPrime1
+}
+
+func xxhMerge(acc, val uint64) uint64 {
+	acc ^= xxhRound(0, val)
+	return acc*xxhPrime1 + xxhPrime4
+}
+
+// Xxh64 computes XXH64(seed: 0).
+func Xxh64(data []byte) uint64 {
+	var n int = len(data)
+	var h uint64
+	var p []byte = data
+	if 32 <= n {
+		var v1 uint64 = xxhPrime1 + xxhPrime2
+		var v2 uint64 = xxhPrime2
+		var v3 uint64 = 0
+		var v4 uint64 = -xxhPrime1
+		for ; 32 <= len(p); p = p[32:] {
+			v1 = xxhRound(v1, binary.LittleEndian.Uint64(p[0:8]))
+			v2 = xxhRound(v2, binary.LittleEndian.Uint64(p[8:16]))
+			v3 = xxhRound(v3, binary.LittleEndian.Uint64(p[16:24]))
+			v4 = xxhRound(v4, binary.LittleEndian.Uint64(p[24:32]))
+		}
+		h = bits.RotateLeft64(v1, 1) +
+			bits.RotateLeft64(v2, 7) +
+			bits.RotateLeft64(v3, 12) +
+			bits.RotateLeft64(v4, 18)
+		h = xxhMerge(h, v1)
+		h = xxhMerge(h, v2)
+		h = xxhMerge(h, v3)
+		h = xxhMerge(h, v4)
+	} else {
+		h = xxhPrime5
+	}
+	h += uint64(n)
+	for ; 8 <= len(p); p = p[8:] {
+		h ^= xxhRound(0, binary.LittleEndian.Uint64(p[0:8]))
+		h = bits.RotateLeft64(h, 27)*xxhPrime1 + xxhPrime4
+	}
+	if 4 <= len(p) {
+		h ^= uint64(binary.LittleEndian.Uint32(p[0:4])) * xxhPrime1
+		h = bits.RotateLeft64(h, 23)*xxhPrime2 + xxhPrime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		h ^= uint64(b) * xxhPrime5
+		h = bits.RotateLeft64(h, 11) * xxhPrime1
+	}
+	h ^= h >> 33
+	h *= xxhPrime2
+	h ^= h >> 29
+	h *= xxhPrime3
+	h ^= h >> 32
+	return h
+}
+
+// ChecksumCrc32c stores CRC32-C using 4 bytes.
+var ChecksumCrc32c Checksum = ChecksumNew(4, Crc32c)
+
+// ChecksumXxh64 stores XXH64 using 8 bytes.
+var ChecksumXxh64 Checksum = ChecksumNew(8, Xxh64)
+
+func (c Checksum) get(stored []byte) uint64 {
+	switch c.size {
+	case 4:
+		return uint64(binary.BigEndian.Uint32(stored))
+	default:
+		return binary.BigEndian.Uint64(stored)
+	}
+}
+
+func (c Checksum) mask(sum uint64) uint64 {
+	switch c.size {
+	case 4:
+		return uint64(uint32(sum))
+	default:
+		return sum
+	}
+}
+
+func (c Checksum) put(dst []byte, sum uint64) []byte {
+	switch c.size {
+	case 4:
+		return binary.BigEndian.AppendUint32(dst, uint32(sum))
+	default:
+		return binary.BigEndian.AppendUint64(dst, sum)
+	}
+}
+
+// Verify checks the checksum of a payload.
+func (c Checksum) Verify(payload []byte, expected uint64) error {
+	var actual uint64 = c.mask(c.sum(payload))
+	if actual != expected {
+		return ChecksumError{Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// AppendTrailer appends a payload and its checksum to dst.
+func (c Checksum) AppendTrailer(dst []byte, payload []byte) []byte {
+	dst = append(dst, payload...)
+	return c.put(dst, c.sum(payload))
+}
+
+// AppendPrefix appends a checksum and the payload to dst.
+func (c Checksum) AppendPrefix(dst []byte, payload []byte) []byte {
+	dst = c.put(dst, c.sum(payload))
+	return append(dst, payload...)
+}
+
+// TrailerNew creates a Decode which verifies a trailing checksum before decoding.
+//
+// Nothing is buffered between items;
+// the Decode is safe for concurrent use if the sum function of c and inner are.
+//
+// # Arguments
+//   - inner: Decodes a verified payload(without the checksum).
+func TrailerNew[D any](c Checksum, inner local.Decode[[]byte, D]) local.Decode[[]byte, D] {
+	return func(encoded []byte) (decoded D, e error) {
+		var split int = len(encoded) - c.size
+		if split < 0 {
+			return decoded, ErrInvalidSize
+		}
+		var payload []byte = encoded[:split]
+		e = c.Verify(payload, c.get(encoded[split:]))
+		if nil != e {
+			return decoded, e
+		}
+		return inner(payload)
+	}
+}
+
+// PrefixNew creates a Decode which verifies a leading checksum before decoding.
+//
+// Like TrailerNew, the Decode is safe for concurrent use if the sum function of c and inner are.
+//
+// # Arguments
+//   - inner: Decodes a verified payload(without the checksum).
+func PrefixNew[D any](c Checksum, inner local.Decode[[]byte, D]) local.Decode[[]byte, D] {
+	return func(encoded []byte) (decoded D, e error) {
+		if len(encoded) < c.size {
+			return decoded, ErrInvalidSize
+		}
+		var payload []byte = encoded[c.size:]
+		e = c.Verify(payload, c.get(encoded[:c.size]))
+		if nil != e {
+			return decoded, e
+		}
+		return inner(payload)
+	}
+}
+
+// Checksummed contains a payload and its side-stored checksum.
+type Checksummed struct {
+	Payload []byte
+	Sum     uint64
+}
+
+// SideNew creates a Decode which verifies a side-stored checksum before decoding.
+//
+// The Decode is as safe for concurrent use as the sum function of c and inner.
+//
+// # Arguments
+//   - inner: Decodes a verified payload.
+func SideNew[D any](c Checksum, inner local.Decode[[]byte, D]) local.Decode[Checksummed, D] {
+	return func(encoded Checksummed) (decoded D, e error) {
+		e = c.Verify(encoded.Payload, encoded.Sum)
+		if nil != e {
+			return decoded, e
+		}
+		return inner(encoded.Payload)
+	}
+}
@@ -0,0 +1,125 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Crc32c", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("check", assertEq(Crc32c([]byte("123456789")), 0xe3069283))
+	})
+
+	t.Run("Xxh64", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("empty", assertEq(Xxh64(nil), 0xef46db3751d8e999))
+		t.Run("abc", assertEq(Xxh64([]byte("abc")), 0x44bc2cf5ad770999))
+		t.Run("long", assertEq(
+			Xxh64([]byte("Nobody inspects the spammish repetition")),
+			0xfbcea83c8a378bf1,
+		))
+	})
+
+	var inner local.Decode[[]byte, testRow] = JsonNew[testRow]()
+
+	t.Run("TrailerNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = TrailerNew(ChecksumCrc32c, inner)
+
+		var encoded []byte = ChecksumCrc32c.AppendTrailer(nil, []byte(`{"minute":42}`))
+		decoded, e := decode(encoded)
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 42))
+
+		encoded[3] ^= 0x01
+		_, e = decode(encoded)
+		t.Run("corrupted", assertEq(errors.Is(e, ErrCorrupted), true))
+
+		var ce ChecksumError
+		t.Run("typed", assertEq(errors.As(e, &ce), true))
+		t.Run("mismatch", assertEq(ce.Expected != ce.Actual, true))
+
+		_, e = decode([]byte{0x01})
+		t.Run("too short", assertEq(errors.Is(e, ErrInvalidSize), true))
+
+		// an unsupported size is clamped to 4 bytes
+		var small Checksum = ChecksumNew(2, Xxh64)
+		encoded = small.AppendTrailer(nil, []byte(`{"minute":7}`))
+		t.Run("4 bytes", assertEq(len(encoded), len(`{"minute":7}`)+4))
+		decoded, e = TrailerNew(small, inner)(encoded)
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 7))
+		_, e = TrailerNew(small, inner)([]byte{0x01, 0x02})
+		t.Run("too short", assertEq(errors.Is(e, ErrInvalidSize), true))
+
+		// an unsupported size is clamped to 8 bytes
+		var large Checksum = ChecksumNew(16, Crc32c)
+		encoded = large.AppendPrefix(nil, []byte(`{"minute":8}`))
+		t.Run("8 bytes", assertEq(len(encoded), len(`{"minute":8}`)+8))
+		decoded, e = PrefixNew(large, inner)(encoded)
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 8))
+	})
+
+	t.Run("PrefixNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = PrefixNew(ChecksumXxh64, inner)
+
+		var encoded []byte = ChecksumXxh64.AppendPrefix(nil, []byte(`{"minute":634}`))
+		decoded, e := decode(encoded)
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 634))
+
+		encoded[len(encoded)-2] ^= 0x80
+		_, e = decode(encoded)
+		t.Run("corrupted", assertEq(errors.Is(e, ErrCorrupted), true))
+	})
+
+	t.Run("SideNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[Checksummed, testRow] = SideNew(ChecksumXxh64, inner)
+
+		var payload []byte = []byte(`{"minute":3776}`)
+		decoded, e := decode(Checksummed{Payload: payload, Sum: Xxh64(payload)})
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 3776))
+
+		_, e = decode(Checksummed{Payload: payload, Sum: 0})
+		t.Run("corrupted", assertEq(errors.Is(e, ErrCorrupted), true))
+	})
+
+	t.Run("with bucket context", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = TrailerNew(ChecksumCrc32c, inner)
+		getDecoded := decode.NewAllWithPolicy(
+			func(_ context.Context, _b local.Bucket) ([][]byte, error) {
+				var valid []byte = ChecksumCrc32c.AppendTrailer(nil, []byte(`{"minute":1}`))
+				var invalid []byte = ChecksumCrc32c.AppendTrailer(nil, []byte(`{"minute":2}`))
+				invalid[0] ^= 0xff
+				return [][]byte{valid, invalid}, nil
+			},
+			local.ErrorPolicySkipCollect,
+		)
+		decoded, report, e := getDecoded(context.Background(), local.BucketNew("2023_01_01"))
+		t.Run("no error", assertNil(e))
+		t.Run("single item", assertEq(len(decoded), 1))
+		t.Run("single error", assertEq(len(report.Errors()), 1))
+
+		var ie local.ItemError = report.Errors()[0]
+		t.Run("corrupted", assertEq(errors.Is(ie, ErrCorrupted), true))
+		t.Run("bucket", assertEq(ie.Bucket().AsString(), "2023_01_01"))
+		t.Run("index", assertEq(ie.Index(), 1))
+	})
+}
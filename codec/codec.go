@@ -0,0 +1,20 @@
+// Package codec provides Decode implementations backed by the standard library.
+//
+// Constructors follow the concurrency contract of the parent package:
+// each one documents whether the returned closure can be used by several goroutines at once.
+package codec
+
+import (
+	"errors"
+)
+
+var (
+	// ErrInvalidSize is returned when the size of an encoded item does not match.
+	ErrInvalidSize = errors.New("invalid size")
+
+	// ErrInvalidRecord is returned when a csv record can not be decoded.
+	ErrInvalidRecord = errors.New("invalid record")
+
+	// ErrUnsupportedType is returned when a field type is not supported.
+	ErrUnsupportedType = errors.New("unsupported type")
+)
@@ -0,0 +1,711 @@
+package codec
+
+import (
+	"encoding/binary"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// ColumnKind is the value type of a column.
+type ColumnKind uint8
+
+const (
+	// ColumnKindInt64 stores int64 values(zigzag varint).
+	ColumnKindInt64 ColumnKind = iota
+
+	// ColumnKindString stores length-prefixed strings.
+	ColumnKindString
+)
+
+// ColumnEncoding is the encoding of a column.
+type ColumnEncoding uint8
+
+const (
+	// ColumnPlain stores a value for each row.
+	ColumnPlain ColumnEncoding = iota
+
+	// ColumnDict stores distinct values and an index for each row.
+	ColumnDict
+
+	// ColumnRle stores runs of the same value.
+	ColumnRle
+)
+
+type columnCodec[T comparable] struct {
+	read  func(b []byte) (value T, n int)
+	write func(dst []byte, value T) []byte
+}
+
+var int64Codec columnCodec[int64] = columnCodec[int64]{
+	read:  binary.Varint,
+	write: binary.AppendVarint,
+}
+
+var stringCodec columnCodec[string] = columnCodec[string]{
+	read: func(b []byte) (string, int) {
+		size, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < size {
+			return "", 0
+		}
+		var ube int = n + int(size)
+		return string(b[n:ube]), ube
+	},
+	write: func(dst []byte, value string) []byte {
+		dst = binary.AppendUvarint(dst, uint64(len(value)))
+		return append(dst, value...)
+	},
+}
+
+func uvarintRead(b []byte) (value uint64, rest []byte, e error) {
+	value, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, nil, ErrInvalidSize
+	}
+	return value, b[n:], nil
+}
+
+func (c columnCodec[T]) next(b []byte) (value T, rest []byte, e error) {
+	value, n := c.read(b)
+	if n <= 0 {
+		return value, nil, ErrInvalidSize
+	}
+	return value, b[n:], nil
+}
+
+// readDict reads distinct values and row indices.
+func (c columnCodec[T]) readDict(
+	body []byte,
+	rows int,
+	dict []T,
+	indices []uint64,
+) ([]T, []uint64, error) {
+	size, body, e := uvarintRead(body)
+	if nil != e {
+		return nil, nil, e
+	}
+	for i := uint64(0); i < size; i++ {
+		var value T
+		value, body, e = c.next(body)
+		if nil != e {
+			return nil, nil, e
+		}
+		dict = append(dict, value)
+	}
+	for row := 0; row < rows; row++ {
+		var ix uint64
+		ix, body, e = uvarintRead(body)
+		if nil != e {
+			return nil, nil, e
+		}
+		if size <= ix {
+			return nil, nil, ErrInvalidRecord
+		}
+		indices = append(indices, ix)
+	}
+	return dict, indices, nil
+}
+
+// eachRun passes runs(value, first row, row after the last row) to f.
+func (c columnCodec[T]) eachRun(body []byte, rows int, f func(value T, lbi, ube int)) error {
+	var lbi int = 0
+	for 0 < len(body) {
+		run, rest, e := uvarintRead(body)
+		if nil != e {
+			return e
+		}
+		value, rest, e := c.next(rest)
+		if nil != e {
+			return e
+		}
+		body = rest
+		if uint64(rows-lbi) < run {
+			return ErrInvalidRecord
+		}
+		var ube int = lbi + int(run)
+		f(value, lbi, ube)
+		lbi = ube
+	}
+	if rows != lbi {
+		return ErrInvalidRecord
+	}
+	return nil
+}
+
+// check checks if a column body has exactly rows values without decoding them.
+func (c columnCodec[T]) check(enc ColumnEncoding, body []byte, rows int) error {
+	switch enc {
+	case ColumnPlain:
+		// a value uses 1 byte at least
+		if len(body) < rows {
+			return ErrInvalidSize
+		}
+		for row := 0; row < rows; row++ {
+			_, n := c.read(body)
+			if n <= 0 {
+				return ErrInvalidSize
+			}
+			body = body[n:]
+		}
+	case ColumnDict:
+		size, rest, e := uvarintRead(body)
+		if nil != e {
+			return e
+		}
+		if uint64(len(rest)) < size {
+			return ErrInvalidSize
+		}
+		for i := uint64(0); i < size; i++ {
+			_, n := c.read(rest)
+			if n <= 0 {
+				return ErrInvalidSize
+			}
+			rest = rest[n:]
+		}
+		// an index uses 1 byte at least
+		if len(rest) < rows {
+			return ErrInvalidSize
+		}
+		for row := 0; row < rows; row++ {
+			var ix uint64
+			ix, rest, e = uvarintRead(rest)
+			if nil != e {
+				return e
+			}
+			if size <= ix {
+				return ErrInvalidRecord
+			}
+		}
+		body = rest
+	case ColumnRle:
+		return c.eachRun(body, rows, func(_ T, _, _ int) {})
+	default:
+		return ErrUnsupportedType
+	}
+	if 0 != len(body) {
+		return ErrInvalidRecord
+	}
+	return nil
+}
+
+func (c columnCodec[T]) decode(enc ColumnEncoding, body []byte, rows int, dst []T) ([]T, error) {
+	switch enc {
+	case ColumnPlain:
+		for row := 0; row < rows; row++ {
+			var value T
+			var e error
+			value, body, e = c.next(body)
+			if nil != e {
+				return nil, e
+			}
+			dst = append(dst, value)
+		}
+		return dst, nil
+	case ColumnDict:
+		dict, indices, e := c.readDict(body, rows, nil, nil)
+		if nil != e {
+			return nil, e
+		}
+		for _, ix := range indices {
+			dst = append(dst, dict[ix])
+		}
+		return dst, nil
+	case ColumnRle:
+		e := c.eachRun(body, rows, func(value T, lbi, ube int) {
+			for row := lbi; row < ube; row++ {
+				dst = append(dst, value)
+			}
+		})
+		return dst, e
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func (c columnCodec[T]) encode(dst []byte, enc ColumnEncoding, values []T) []byte {
+	switch enc {
+	case ColumnDict:
+		var ids map[T]uint64 = map[T]uint64{}
+		var dict []T
+		for _, value := range values {
+			_, found := ids[value]
+			if !found {
+				ids[value] = uint64(len(dict))
+				dict = append(dict, value)
+			}
+		}
+		dst = binary.AppendUvarint(dst, uint64(len(dict)))
+		for _, value := range dict {
+			dst = c.write(dst, value)
+		}
+		for _, value := range values {
+			dst = binary.AppendUvarint(dst, ids[value])
+		}
+		return dst
+	case ColumnRle:
+		for lbi := 0; lbi < len(values); {
+			var ube int = lbi + 1
+			for ube < len(values) && values[ube] == values[lbi] {
+				ube += 1
+			}
+			dst = binary.AppendUvarint(dst, uint64(ube-lbi))
+			dst = c.write(dst, values[lbi])
+			lbi = ube
+		}
+		return dst
+	default:
+		for _, value := range values {
+			dst = c.write(dst, value)
+		}
+		return dst
+	}
+}
+
+// sel keeps selected rows(ascending) whose value is kept.
+//
+// All rows are selected if selected is nil; kept rows are appended to kept.
+// Dictionary values are checked once and runs are checked once.
+func (c columnCodec[T]) sel(
+	enc ColumnEncoding,
+	body []byte,
+	rows int,
+	decoded func() ([]T, error),
+	selected []int,
+	kept []int,
+	keep func(value T) bool,
+) ([]int, error) {
+	var all bool = nil == selected
+	var count int = len(selected)
+	if all {
+		count = rows
+	}
+	at := func(ix int) int {
+		if all {
+			return ix
+		}
+		return selected[ix]
+	}
+	switch enc {
+	case ColumnDict:
+		dict, indices, e := c.readDict(body, rows, nil, nil)
+		if nil != e {
+			return nil, e
+		}
+		var keepDict []bool = make([]bool, len(dict))
+		for ix, value := range dict {
+			keepDict[ix] = keep(value)
+		}
+		for ix := 0; ix < count; ix++ {
+			var row int = at(ix)
+			if keepDict[indices[row]] {
+				kept = append(kept, row)
+			}
+		}
+		return kept, nil
+	case ColumnRle:
+		var ix int = 0
+		e := c.eachRun(body, rows, func(value T, lbi, ube int) {
+			if all {
+				// a rejected run is skipped without visiting its rows
+				if keep(value) {
+					for row := lbi; row < ube; row++ {
+						kept = append(kept, row)
+					}
+				}
+				return
+			}
+			for ix < count && at(ix) < lbi {
+				ix += 1
+			}
+			if count <= ix || ube <= at(ix) {
+				return
+			}
+			var keepRun bool = keep(value)
+			for ; ix < count && at(ix) < ube; ix++ {
+				if keepRun {
+					kept = append(kept, at(ix))
+				}
+			}
+		})
+		return kept, e
+	default:
+		values, e := decoded()
+		if nil != e {
+			return nil, e
+		}
+		for ix := 0; ix < count; ix++ {
+			var row int = at(ix)
+			if keep(values[row]) {
+				kept = append(kept, row)
+			}
+		}
+		return kept, nil
+	}
+}
+
+// ColumnarWriter creates a columnar block.
+type ColumnarWriter struct {
+	rows int
+	cols int
+	body []byte
+}
+
+// ColumnarWriterNew creates a ColumnarWriter for a block which has the rows.
+func ColumnarWriterNew(rows int) *ColumnarWriter { return &ColumnarWriter{rows: rows} }
+
+func (w *ColumnarWriter) header(kind ColumnKind, enc ColumnEncoding, body []byte) {
+	w.cols += 1
+	w.body = append(w.body, byte(kind), byte(enc))
+	w.body = binary.AppendUvarint(w.body, uint64(len(body)))
+	w.body = append(w.body, body...)
+}
+
+// Int64s appends an int64 column.
+func (w *ColumnarWriter) Int64s(enc ColumnEncoding, values []int64) error {
+	if len(values) != w.rows {
+		return ErrInvalidSize
+	}
+	w.header(ColumnKindInt64, enc, int64Codec.encode(nil, enc, values))
+	return nil
+}
+
+// Strings appends a string column.
+func (w *ColumnarWriter) Strings(enc ColumnEncoding, values []string) error {
+	if len(values) != w.rows {
+		return ErrInvalidSize
+	}
+	w.header(ColumnKindString, enc, stringCodec.encode(nil, enc, values))
+	return nil
+}
+
+// AppendTo appends the block to dst.
+//
+// Layout: rows(uvarint) | columns(uvarint) | (kind | encoding | size(uvarint) | body)...
+func (w *ColumnarWriter) AppendTo(dst []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(w.rows))
+	dst = binary.AppendUvarint(dst, uint64(w.cols))
+	return append(dst, w.body...)
+}
+
+type columnarColumn struct {
+	kind    ColumnKind
+	enc     ColumnEncoding
+	body    []byte
+	decoded bool
+	ints    []int64
+	strs    []string
+}
+
+// ColumnarMaxRows is the default max number of rows of a ColumnarBlock.
+//
+// A run of a rle column can claim many rows using a few bytes;
+// rows are limited before any per-row allocation.
+const ColumnarMaxRows int = 1 << 20
+
+// ColumnarBlock is a parsed columnar block.
+//
+// Columns are decoded lazily and cached until the next Reset.
+// The zero value accepts at most ColumnarMaxRows rows.
+type ColumnarBlock struct {
+	rows    int
+	maxRows int
+	cols    []columnarColumn
+	kept    []int
+}
+
+// ColumnarBlockNew creates a ColumnarBlock which accepts at most maxRows rows.
+//
+// # Arguments
+//   - maxRows: Max number of rows(ColumnarMaxRows if not positive).
+func ColumnarBlockNew(maxRows int) *ColumnarBlock {
+	return &ColumnarBlock{maxRows: maxRows}
+}
+
+func (b *ColumnarBlock) rowsLimit() uint64 {
+	if b.maxRows <= 0 {
+		return uint64(ColumnarMaxRows)
+	}
+	return uint64(b.maxRows)
+}
+
+// keptBuf gets a buffer for kept rows(selected itself unless all rows are selected).
+func (b *ColumnarBlock) keptBuf(selected []int) []int {
+	if nil != selected {
+		return selected[:0]
+	}
+	return b.kept[:0]
+}
+
+// keptSave saves a buffer got by keptBuf for the next block.
+func (b *ColumnarBlock) keptSave(selected []int, kept []int) {
+	if nil == selected && cap(b.kept) < cap(kept) {
+		b.kept = kept
+	}
+}
+
+// Reset parses a packed block(the block must not be modified until the next Reset).
+//
+// Every column must have exactly rows values; a block without columns must have no rows.
+// A block which has more rows than the limit of b is rejected(ErrInvalidSize).
+func (b *ColumnarBlock) Reset(packed []byte) error {
+	b.rows = 0
+	b.cols = b.cols[:0]
+	rows, rest, e := uvarintRead(packed)
+	if nil != e {
+		return e
+	}
+	if b.rowsLimit() < rows {
+		return ErrInvalidSize
+	}
+	cols, rest, e := uvarintRead(rest)
+	if nil != e {
+		return e
+	}
+	if 0 == cols && 0 != rows {
+		return ErrInvalidSize
+	}
+	// a column header(kind, encoding, size) uses 3 bytes at least
+	if uint64(len(rest))/3 < cols {
+		return ErrInvalidSize
+	}
+	var reuse []columnarColumn = b.cols[:cap(b.cols)]
+	for i := uint64(0); i < cols; i++ {
+		if len(rest) < 2 {
+			return ErrInvalidSize
+		}
+		var kind ColumnKind = ColumnKind(rest[0])
+		var enc ColumnEncoding = ColumnEncoding(rest[1])
+		size, body, e := uvarintRead(rest[2:])
+		if nil != e {
+			return e
+		}
+		if uint64(len(body)) < size {
+			return ErrInvalidSize
+		}
+		e = columnCheck(kind, enc, body[:size], rows)
+		if nil != e {
+			return e
+		}
+		var c columnarColumn
+		if int(i) < len(reuse) {
+			c = reuse[i]
+		}
+		c.kind = kind
+		c.enc = enc
+		c.body = body[:size]
+		c.decoded = false
+		b.cols = append(b.cols, c)
+		rest = body[size:]
+	}
+	b.rows = int(rows)
+	return nil
+}
+
+func columnCheck(kind ColumnKind, enc ColumnEncoding, body []byte, rows uint64) error {
+	// rle can not use more rows than int
+	if uint64(^uint(0)>>1) < rows {
+		return ErrInvalidSize
+	}
+	switch kind {
+	case ColumnKindInt64:
+		return int64Codec.check(enc, body, int(rows))
+	case ColumnKindString:
+		return stringCodec.check(enc, body, int(rows))
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+// Rows returns the number of rows.
+func (b *ColumnarBlock) Rows() int { return b.rows }
+
+func (b *ColumnarBlock) column(col int, kind ColumnKind) (*columnarColumn, error) {
+	if col < 0 || len(b.cols) <= col {
+		return nil, ErrInvalidRecord
+	}
+	var c *columnarColumn = &b.cols[col]
+	if kind != c.kind {
+		return nil, ErrUnsupportedType
+	}
+	return c, nil
+}
+
+func (b *ColumnarBlock) int64s(col int) ([]int64, error) {
+	c, e := b.column(col, ColumnKindInt64)
+	if nil != e {
+		return nil, e
+	}
+	if !c.decoded {
+		c.ints, e = int64Codec.decode(c.enc, c.body, b.rows, c.ints[:0])
+		if nil != e {
+			return nil, e
+		}
+		c.decoded = true
+	}
+	return c.ints, nil
+}
+
+func (b *ColumnarBlock) strings(col int) ([]string, error) {
+	c, e := b.column(col, ColumnKindString)
+	if nil != e {
+		return nil, e
+	}
+	if !c.decoded {
+		c.strs, e = stringCodec.decode(c.enc, c.body, b.rows, c.strs[:0])
+		if nil != e {
+			return nil, e
+		}
+		c.decoded = true
+	}
+	return c.strs, nil
+}
+
+// Int64 gets a value of an int64 column.
+func (b *ColumnarBlock) Int64(col int, row int) (int64, error) {
+	if row < 0 || b.rows <= row {
+		return 0, ErrInvalidRecord
+	}
+	values, e := b.int64s(col)
+	if nil != e {
+		return 0, e
+	}
+	return values[row], nil
+}
+
+// String gets a value of a string column.
+func (b *ColumnarBlock) String(col int, row int) (string, error) {
+	if row < 0 || b.rows <= row {
+		return "", ErrInvalidRecord
+	}
+	values, e := b.strings(col)
+	if nil != e {
+		return "", e
+	}
+	return values[row], nil
+}
+
+// ColumnPredicate must keep selected rows(ascending) which may be required by a filter.
+//
+// All rows are selected if selected is nil(the first predicate of ColumnarNew).
+type ColumnPredicate[F any] func(block *ColumnarBlock, selected []int, filter *F) (
+	kept []int,
+	e error,
+)
+
+// ColumnInt64Where creates a ColumnPredicate which checks an int64 column.
+//
+// The predicate keeps no state of its own; its buffers belong to the block.
+func ColumnInt64Where[F any](col int, keep func(value int64, filter *F) bool) ColumnPredicate[F] {
+	return func(block *ColumnarBlock, selected []int, filter *F) ([]int, error) {
+		c, e := block.column(col, ColumnKindInt64)
+		if nil != e {
+			return nil, e
+		}
+		kept, e := int64Codec.sel(
+			c.enc,
+			c.body,
+			block.rows,
+			func() ([]int64, error) { return block.int64s(col) },
+			selected,
+			block.keptBuf(selected),
+			func(value int64) bool { return keep(value, filter) },
+		)
+		block.keptSave(selected, kept)
+		return kept, e
+	}
+}
+
+// ColumnStringWhere creates a ColumnPredicate which checks a string column.
+//
+// Like ColumnInt64Where, it can be shared if each goroutine uses its own block.
+func ColumnStringWhere[F any](col int, keep func(value string, filter *F) bool) ColumnPredicate[F] {
+	return func(block *ColumnarBlock, selected []int, filter *F) ([]int, error) {
+		c, e := block.column(col, ColumnKindString)
+		if nil != e {
+			return nil, e
+		}
+		kept, e := stringCodec.sel(
+			c.enc,
+			c.body,
+			block.rows,
+			func() ([]string, error) { return block.strings(col) },
+			selected,
+			block.keptBuf(selected),
+			func(value string) bool { return keep(value, filter) },
+		)
+		block.keptSave(selected, kept)
+		return kept, e
+	}
+}
+
+// ColumnarNew creates an UnnestFiltered which evaluates predicates column at a time.
+//
+// Rows are materialized only if all predicates keep them.
+// A block which has more than ColumnarMaxRows rows is rejected.
+// The returned UnnestFiltered reuses its buffers and must not be used concurrently.
+//
+// # Arguments
+//   - predicates: Checks columns(all predicates must keep a row).
+//   - materialize: Gets an unnested item from a selected row.
+func ColumnarNew[U, F any](
+	predicates []ColumnPredicate[F],
+	materialize func(block *ColumnarBlock, row int) (unnested U, e error),
+) local.UnnestFiltered[[]byte, U, F] {
+	return ColumnarNewWithMaxRows(ColumnarMaxRows, predicates, materialize)
+}
+
+// ColumnarNewWithMaxRows creates an UnnestFiltered like ColumnarNew using a rows limit.
+//
+// The returned UnnestFiltered reuses its buffers and must not be used concurrently.
+//
+// # Arguments
+//   - maxRows: Max number of rows of a block(ColumnarMaxRows if not positive).
+//   - predicates: Checks columns(all predicates must keep a row).
+//   - materialize: Gets an unnested item from a selected row.
+func ColumnarNewWithMaxRows[U, F any](
+	maxRows int,
+	predicates []ColumnPredicate[F],
+	materialize func(block *ColumnarBlock, row int) (unnested U, e error),
+) local.UnnestFiltered[[]byte, U, F] {
+	var block *ColumnarBlock = ColumnarBlockNew(maxRows)
+	var all []int
+	return func(
+		packed *[]byte,
+		filter *F,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		e = block.Reset(*packed)
+		if nil != e {
+			return true, e
+		}
+		// nil selects all rows without a row index for each row
+		var selected []int = nil
+		if 0 == len(predicates) {
+			all = all[:0]
+			for row := 0; row < block.rows; row++ {
+				all = append(all, row)
+			}
+			selected = all
+		}
+		for ix, predicate := range predicates {
+			if 0 < ix && 0 == len(selected) {
+				return false, nil
+			}
+			selected, e = predicate(block, selected, filter)
+			if nil != e {
+				return true, e
+			}
+		}
+		for _, row := range selected {
+			*buf, e = materialize(block, row)
+			if nil != e {
+				return true, e
+			}
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		return false, nil
+	}
+}
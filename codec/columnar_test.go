@@ -0,0 +1,258 @@
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+type testColumnarFilter struct {
+	minute int64
+	label  string
+}
+
+func testColumnarBlock(t *testing.T) []byte {
+	var w *ColumnarWriter = ColumnarWriterNew(6)
+	t.Run("minute", assertNil(w.Int64s(ColumnRle, []int64{1, 1, 1, 2, 2, 3})))
+	t.Run("second", assertNil(w.Int64s(ColumnPlain, []int64{0, 10, -20, 30, 40, 50})))
+	t.Run("label", assertNil(w.Strings(ColumnDict, []string{"a", "b", "a", "a", "c", "a"})))
+	return w.AppendTo(nil)
+}
+
+func TestColumnar(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ColumnarBlock", func(t *testing.T) {
+		t.Parallel()
+
+		var packed []byte = testColumnarBlock(t)
+		var block ColumnarBlock
+		t.Run("parsed", assertNil(block.Reset(packed)))
+		t.Run("6 rows", assertEq(block.Rows(), 6))
+
+		minute, e := block.Int64(0, 4)
+		t.Run("no error", assertNil(e))
+		t.Run("rle", assertEq(minute, 2))
+
+		second, e := block.Int64(1, 2)
+		t.Run("no error", assertNil(e))
+		t.Run("plain", assertEq(second, -20))
+
+		label, e := block.String(2, 4)
+		t.Run("no error", assertNil(e))
+		t.Run("dict", assertEq(label, "c"))
+
+		_, e = block.String(0, 0)
+		t.Run("kind mismatch", assertEq(errors.Is(e, ErrUnsupportedType), true))
+
+		_, e = block.Int64(3, 0)
+		t.Run("no column", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		_, e = block.Int64(0, 6)
+		t.Run("row out of range", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		_, e = block.String(2, -1)
+		t.Run("negative row", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		t.Run("truncated", assertEq(nil != block.Reset(packed[:len(packed)-3]), true))
+	})
+
+	t.Run("corrupted rows", func(t *testing.T) {
+		t.Parallel()
+
+		var block ColumnarBlock
+
+		// rows=1<<31 without columns
+		var noColumns []byte = binary.AppendUvarint(nil, 1<<31)
+		noColumns = binary.AppendUvarint(noColumns, 0)
+		e := block.Reset(noColumns)
+		t.Run("no columns", assertEq(errors.Is(e, ErrInvalidSize), true))
+
+		// rows=1<<31 with a single plain column
+		var w *ColumnarWriter = ColumnarWriterNew(2)
+		t.Run("plain", assertNil(w.Int64s(ColumnPlain, []int64{1, 2})))
+		var plain []byte = w.AppendTo(nil)
+		var corrupt []byte = binary.AppendUvarint(nil, 1<<31)
+		corrupt = append(corrupt, plain[1:]...)
+		e = block.Reset(corrupt)
+		t.Run("too many rows", assertEq(errors.Is(e, ErrInvalidSize), true))
+
+		var rle *ColumnarWriter = ColumnarWriterNew(3)
+		t.Run("rle", assertNil(rle.Int64s(ColumnRle, []int64{1, 1, 1})))
+		var runs []byte = rle.AppendTo(nil)
+		runs[0] = 2
+		e = block.Reset(runs)
+		t.Run("run exceeds rows", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		runs[0] = 4
+		e = block.Reset(runs)
+		t.Run("runs lack rows", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		// a huge run must not overflow
+		var huge []byte = binary.AppendUvarint(nil, 3)
+		huge = binary.AppendUvarint(huge, 1)
+		var body []byte = binary.AppendUvarint(nil, 1<<63)
+		body = binary.AppendVarint(body, 7)
+		body = binary.AppendUvarint(body, 4)
+		body = binary.AppendVarint(body, 7)
+		huge = append(huge, byte(ColumnKindInt64), byte(ColumnRle))
+		huge = binary.AppendUvarint(huge, uint64(len(body)))
+		huge = append(huge, body...)
+		e = block.Reset(huge)
+		t.Run("huge run", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		materialized := 0
+		f := ColumnarNew[int, uint8](
+			nil,
+			func(_ *ColumnarBlock, row int) (int, error) {
+				materialized += 1
+				return row, nil
+			},
+		)
+		var buf int
+		_, e = f(&corrupt, nil, &buf, func(_ *int) (bool, error) { return false, nil })
+		t.Run("rejected", assertEq(errors.Is(e, ErrInvalidSize), true))
+		t.Run("nothing materialized", assertEq(materialized, 0))
+	})
+
+	t.Run("rle rows limit", func(t *testing.T) {
+		t.Parallel()
+
+		// rows=2^40 with an int64 rle column which has a single run of 2^40
+		var packed []byte = binary.AppendUvarint(nil, 1<<40)
+		packed = binary.AppendUvarint(packed, 1)
+		var body []byte = binary.AppendUvarint(nil, 1<<40)
+		body = binary.AppendVarint(body, 7)
+		packed = append(packed, byte(ColumnKindInt64), byte(ColumnRle))
+		packed = binary.AppendUvarint(packed, uint64(len(body)))
+		packed = append(packed, body...)
+		t.Run("17 bytes", assertEq(len(packed), 17))
+
+		var block ColumnarBlock
+		e := block.Reset(packed)
+		t.Run("rejected", assertEq(errors.Is(e, ErrInvalidSize), true))
+		t.Run("no rows", assertEq(block.Rows(), 0))
+		_, e = block.Int64(0, 0)
+		t.Run("no column", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		materialized := 0
+		keepSeven := []ColumnPredicate[int64]{
+			ColumnInt64Where(0, func(value int64, f *int64) bool { return value == *f }),
+		}
+		materialize := func(_ *ColumnarBlock, row int) (int, error) {
+			materialized += 1
+			return row, nil
+		}
+		var buf int
+		each := func(_ *int) (bool, error) { return true, nil }
+		var other int64 = 8
+
+		f := ColumnarNew[int, int64](keepSeven, materialize)
+		_, e = f(&packed, &other, &buf, each)
+		t.Run("ColumnarNew rejected", assertEq(errors.Is(e, ErrInvalidSize), true))
+
+		// a raised limit accepts the block; a rejected run allocates no row index
+		var raised *ColumnarBlock = ColumnarBlockNew(1 << 41)
+		t.Run("raised limit", assertNil(raised.Reset(packed)))
+		t.Run("2^40 rows", assertEq(raised.Rows(), 1<<40))
+
+		f = ColumnarNewWithMaxRows[int, int64](1<<41, keepSeven, materialize)
+		stop, e := f(&packed, &other, &buf, each)
+		t.Run("no error", assertNil(e))
+		t.Run("not stopped", assertEq(stop, false))
+		t.Run("nothing materialized", assertEq(materialized, 0))
+	})
+
+	t.Run("ColumnarNew", func(t *testing.T) {
+		t.Parallel()
+
+		var packed []byte = testColumnarBlock(t)
+
+		var minuteChecks int = 0
+		var labelChecks int = 0
+		var materialized int = 0
+		var unnest local.UnnestFiltered[[]byte, testRow, testColumnarFilter] = ColumnarNew(
+			[]ColumnPredicate[testColumnarFilter]{
+				ColumnInt64Where(0, func(minute int64, f *testColumnarFilter) bool {
+					minuteChecks += 1
+					return minute <= f.minute
+				}),
+				ColumnStringWhere(2, func(label string, f *testColumnarFilter) bool {
+					labelChecks += 1
+					return label == f.label
+				}),
+			},
+			func(block *ColumnarBlock, row int) (r testRow, e error) {
+				materialized += 1
+				minute, _ := block.Int64(0, row)
+				second, _ := block.Int64(1, row)
+				r.Label, e = block.String(2, row)
+				r.Minute = int32(minute)
+				r.Score = float64(second)
+				return
+			},
+		)
+
+		var rows []testRow
+		run := local.PipelineSliceNew(
+			local.PipeUnnestFiltered(local.PipelineNew[[]byte, testColumnarFilter](), unnest),
+			func(r *testRow, _ *testColumnarFilter) (stop bool, e error) {
+				rows = append(rows, *r)
+				return
+			},
+		)
+
+		var filter testColumnarFilter = testColumnarFilter{minute: 2, label: "a"}
+		e := run(context.Background(), [][]byte{packed}, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("3 rows", assertEq(len(rows), 3))
+		t.Run("row 2", assertEq(rows[2].Score, 30))
+		t.Run("3 runs checked", assertEq(minuteChecks, 3))
+		t.Run("3 dict values checked", assertEq(labelChecks, 3))
+		t.Run("3 rows materialized", assertEq(materialized, 3))
+
+		rows = nil
+		filter = testColumnarFilter{minute: 0, label: "a"}
+		e = run(context.Background(), [][]byte{packed}, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("no rows", assertEq(len(rows), 0))
+
+		// plain and dict columns as the first predicate
+		type firstPredicate struct {
+			predicate ColumnPredicate[testColumnarFilter]
+			kept      int
+		}
+		var firsts []firstPredicate = []firstPredicate{
+			{
+				predicate: ColumnInt64Where(1, func(second int64, _ *testColumnarFilter) bool {
+					return 30 <= second
+				}),
+				kept: 3,
+			},
+			{
+				predicate: ColumnStringWhere(2, func(label string, f *testColumnarFilter) bool {
+					return label == f.label
+				}),
+				kept: 4,
+			},
+		}
+		for _, first := range firsts {
+			var kept []int
+			unnestFirst := ColumnarNew(
+				[]ColumnPredicate[testColumnarFilter]{first.predicate},
+				func(_ *ColumnarBlock, row int) (int, error) { return row, nil },
+			)
+			var row int
+			_, e = unnestFirst(&packed, &filter, &row, func(r *int) (bool, error) {
+				kept = append(kept, *r)
+				return false, nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("rows kept", assertEq(len(kept), first.kept))
+			t.Run("last row", assertEq(kept[len(kept)-1], 5))
+		}
+	})
+}
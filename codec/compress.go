@@ -0,0 +1,174 @@
+package codec
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"io"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// decompressor reuses a reader state and an output buffer across calls.
+type decompressor struct {
+	src   bytes.Reader
+	buf   bytes.Buffer
+	reset func(src io.Reader) (decompressed io.Reader, e error)
+}
+
+func (d *decompressor) decompress(compressed []byte) (decompressed []byte, e error) {
+	d.src.Reset(compressed)
+	rdr, e := d.reset(&d.src)
+	if nil != e {
+		return nil, e
+	}
+	d.buf.Reset()
+	_, e = d.buf.ReadFrom(rdr)
+	return d.buf.Bytes(), e
+}
+
+func decompressNew[D any](
+	reset func(src io.Reader) (io.Reader, error),
+	inner local.Decode[[]byte, D],
+) local.Decode[[]byte, D] {
+	var d decompressor = decompressor{reset: reset}
+	return func(compressed []byte) (decoded D, e error) {
+		decompressed, e := d.decompress(compressed)
+		if nil != e {
+			return decoded, e
+		}
+		return inner(decompressed)
+	}
+}
+
+func gzipResetNew() func(src io.Reader) (io.Reader, error) {
+	var rdr *gzip.Reader
+	return func(src io.Reader) (io.Reader, error) {
+		if nil == rdr {
+			r, e := gzip.NewReader(src)
+			if nil != e {
+				return nil, e
+			}
+			rdr = r
+			return rdr, nil
+		}
+		return rdr, rdr.Reset(src)
+	}
+}
+
+func zlibResetNew() func(src io.Reader) (io.Reader, error) {
+	var rdr io.ReadCloser
+	return func(src io.Reader) (io.Reader, error) {
+		if nil == rdr {
+			r, e := zlib.NewReader(src)
+			if nil != e {
+				return nil, e
+			}
+			rdr = r
+			return rdr, nil
+		}
+		return rdr, rdr.(zlib.Resetter).Reset(src, nil)
+	}
+}
+
+func flateResetNew() func(src io.Reader) (io.Reader, error) {
+	var rdr io.ReadCloser = flate.NewReader(nil)
+	return func(src io.Reader) (io.Reader, error) {
+		return rdr, rdr.(flate.Resetter).Reset(src, nil)
+	}
+}
+
+func lzwResetNew(order lzw.Order, litWidth int) func(src io.Reader) (io.Reader, error) {
+	var rdr *lzw.Reader = lzw.NewReader(nil, order, litWidth).(*lzw.Reader)
+	return func(src io.Reader) (io.Reader, error) {
+		rdr.Reset(src, order, litWidth)
+		return rdr, nil
+	}
+}
+
+// GzipNew creates a Decode which decompresses gzip bytes before decoding.
+//
+// The returned Decode reuses its state and must not be used concurrently.
+// The inner Decode must not retain the decompressed bytes.
+func GzipNew[D any](inner local.Decode[[]byte, D]) local.Decode[[]byte, D] {
+	return decompressNew(gzipResetNew(), inner)
+}
+
+// ZlibNew creates a Decode which decompresses zlib bytes before decoding.
+//
+// The returned Decode reuses its state and must not be used concurrently.
+// The inner Decode must not retain the decompressed bytes.
+func ZlibNew[D any](inner local.Decode[[]byte, D]) local.Decode[[]byte, D] {
+	return decompressNew(zlibResetNew(), inner)
+}
+
+// FlateNew creates a Decode which decompresses raw deflate bytes before decoding.
+//
+// The returned Decode reuses its state and must not be used concurrently.
+// The inner Decode must not retain the decompressed bytes.
+func FlateNew[D any](inner local.Decode[[]byte, D]) local.Decode[[]byte, D] {
+	return decompressNew(flateResetNew(), inner)
+}
+
+// LzwNew creates a Decode which decompresses lzw bytes before decoding.
+//
+// The returned Decode reuses its state and must not be used concurrently.
+// The inner Decode must not retain the decompressed bytes.
+//
+// # Arguments
+//   - order: lzw.LSB or lzw.MSB.
+//   - litWidth: The number of bits to use for literal codes(2-8).
+//   - inner: Decodes decompressed bytes.
+func LzwNew[D any](
+	order lzw.Order,
+	litWidth int,
+	inner local.Decode[[]byte, D],
+) local.Decode[[]byte, D] {
+	return decompressNew(lzwResetNew(order, litWidth), inner)
+}
+
+func isGzip(b []byte) bool { return 2 <= len(b) && 0x1f == b[0] && 0x8b == b[1] }
+
+// isZlib checks a zlib header(deflate, window <= 32K, no preset dictionary).
+//
+// Some uncompressed payloads(about 1 in 2000) may still match.
+func isZlib(b []byte) bool {
+	if len(b) < 2 {
+		return false
+	}
+	var cmf uint16 = uint16(b[0])
+	var flg uint16 = uint16(b[1])
+	var deflate bool = 8 == (cmf & 0x0f)
+	var window bool = (cmf >> 4) <= 7
+	var noDict bool = 0 == (flg & 0x20)
+	var check bool = 0 == ((cmf<<8)|flg)%31
+	return deflate && window && noDict && check
+}
+
+// AutoNew creates a Decode which detects gzip/zlib magic before decoding.
+//
+// Bytes without known magic are passed to the inner Decode as is.
+// Bytes which look like a zlib header but can not be decompressed
+// are also passed to the inner Decode as is.
+// The returned Decode reuses its state and must not be used concurrently.
+// The inner Decode must not retain the decompressed bytes.
+func AutoNew[D any](inner local.Decode[[]byte, D]) local.Decode[[]byte, D] {
+	var gz local.Decode[[]byte, D] = GzipNew(inner)
+	var zl decompressor = decompressor{reset: zlibResetNew()}
+	return func(encoded []byte) (decoded D, e error) {
+		switch {
+		case isGzip(encoded):
+			return gz(encoded)
+		case isZlib(encoded):
+			decompressed, e := zl.decompress(encoded)
+			if nil != e {
+				return inner(encoded)
+			}
+			return inner(decompressed)
+		default:
+			return inner(encoded)
+		}
+	}
+}
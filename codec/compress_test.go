@@ -0,0 +1,136 @@
+package codec
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"io"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+func testCompress(w io.WriteCloser, raw string) {
+	_, _ = w.Write([]byte(raw))
+	_ = w.Close()
+}
+
+func testGzip(raw string) []byte {
+	var buf bytes.Buffer
+	testCompress(gzip.NewWriter(&buf), raw)
+	return buf.Bytes()
+}
+
+func testZlib(raw string) []byte {
+	var buf bytes.Buffer
+	testCompress(zlib.NewWriter(&buf), raw)
+	return buf.Bytes()
+}
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+
+	var inner local.Decode[[]byte, testRow] = JsonNew[testRow]()
+
+	t.Run("GzipNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = GzipNew(inner)
+
+		decoded, e := decode(testGzip(`{"minute":42}`))
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 42))
+
+		decoded, e = decode(testGzip(`{"minute":634}`))
+		t.Run("reused", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 634))
+
+		_, e = decode([]byte(`{"minute":42}`))
+		t.Run("invalid", assertEq(nil != e, true))
+
+		decoded, e = decode(testGzip(`{"minute":3776}`))
+		t.Run("reusable after error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 3776))
+	})
+
+	t.Run("ZlibNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = ZlibNew(inner)
+
+		decoded, e := decode(testZlib(`{"minute":1}`))
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 1))
+
+		decoded, e = decode(testZlib(`{"minute":2}`))
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 2))
+	})
+
+	t.Run("FlateNew", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+		testCompress(w, `{"minute":599}`)
+
+		var decode local.Decode[[]byte, testRow] = FlateNew(inner)
+		decoded, e := decode(buf.Bytes())
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 599))
+	})
+
+	t.Run("LzwNew", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		testCompress(lzw.NewWriter(&buf, lzw.LSB, 8), `{"minute":333}`)
+
+		var decode local.Decode[[]byte, testRow] = LzwNew(lzw.LSB, 8, inner)
+		decoded, e := decode(buf.Bytes())
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 333))
+	})
+
+	t.Run("AutoNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = AutoNew(inner)
+
+		decoded, e := decode(testGzip(`{"minute":1}`))
+		t.Run("gzip", assertNil(e))
+		t.Run("gzip minute", assertEq(decoded.Minute, 1))
+
+		decoded, e = decode(testZlib(`{"minute":2}`))
+		t.Run("zlib", assertNil(e))
+		t.Run("zlib minute", assertEq(decoded.Minute, 2))
+
+		decoded, e = decode([]byte(`{"minute":3}`))
+		t.Run("raw", assertNil(e))
+		t.Run("raw minute", assertEq(decoded.Minute, 3))
+
+		var raw local.Decode[[]byte, string] = AutoNew(
+			local.Decode[[]byte, string](func(encoded []byte) (string, error) {
+				return string(encoded), nil
+			}),
+		)
+
+		s, e := raw([]byte("x^hello"))
+		t.Run("zlib-like text", assertNil(e))
+		t.Run("zlib-like text as is", assertEq(s, "x^hello"))
+
+		s, e = raw([]byte{0x08, 0x1d, 0x10, 0x01})
+		t.Run("zlib-like protobuf", assertNil(e))
+		t.Run("zlib-like protobuf as is", assertEq(s, string([]byte{0x08, 0x1d, 0x10, 0x01})))
+
+		s, e = raw([]byte{0x88, 0x1c, 0x01})
+		t.Run("large window", assertNil(e))
+		t.Run("large window as is", assertEq(s, string([]byte{0x88, 0x1c, 0x01})))
+
+		s, e = raw(testZlib("hello"))
+		t.Run("zlib", assertNil(e))
+		t.Run("zlib decompressed", assertEq(s, "hello"))
+	})
+}
@@ -0,0 +1,93 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strconv"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+func csvSetField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+		return nil
+	case reflect.Bool:
+		b, e := strconv.ParseBool(raw)
+		field.SetBool(b)
+		return e
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, e := strconv.ParseInt(raw, 10, field.Type().Bits())
+		field.SetInt(i)
+		return e
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, e := strconv.ParseUint(raw, 10, field.Type().Bits())
+		field.SetUint(u)
+		return e
+	case reflect.Float32, reflect.Float64:
+		f, e := strconv.ParseFloat(raw, field.Type().Bits())
+		field.SetFloat(f)
+		return e
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+// CsvRecordNew creates a Decode which decodes a csv record into a struct.
+//
+// Columns are assigned to exported fields in declaration order.
+// Fields tagged with `csv:"-"` are skipped.
+// Supported field kinds: string, bool, ints, uints and floats.
+//
+// The field layout of D is computed once and only read afterwards;
+// the Decode is safe for concurrent use.
+func CsvRecordNew[D any]() local.Decode[[]string, D] {
+	var typ reflect.Type = reflect.TypeOf((*D)(nil)).Elem()
+	var fields []int
+	if reflect.Struct == typ.Kind() {
+		for i := 0; i < typ.NumField(); i++ {
+			var f reflect.StructField = typ.Field(i)
+			if !f.IsExported() || "-" == f.Tag.Get("csv") {
+				continue
+			}
+			fields = append(fields, i)
+		}
+	}
+	return func(record []string) (decoded D, e error) {
+		if reflect.Struct != typ.Kind() {
+			return decoded, ErrUnsupportedType
+		}
+		if len(record) != len(fields) {
+			return decoded, ErrInvalidRecord
+		}
+		var val reflect.Value = reflect.ValueOf(&decoded).Elem()
+		for col, ix := range fields {
+			e = csvSetField(val.Field(ix), record[col])
+			if nil != e {
+				return decoded, e
+			}
+		}
+		return
+	}
+}
+
+// CsvNew creates a Decode which decodes a csv line into a struct.
+//
+// A csv reader is created for each line so that the Decode is safe for concurrent use.
+//
+// # Arguments
+//   - comma: The field delimiter(e.g, ',' or '\t').
+func CsvNew[D any](comma rune) local.Decode[[]byte, D] {
+	var fromRecord local.Decode[[]string, D] = CsvRecordNew[D]()
+	return func(encoded []byte) (decoded D, e error) {
+		var r *csv.Reader = csv.NewReader(bytes.NewReader(encoded))
+		r.Comma = comma
+		record, e := r.Read()
+		if nil != e {
+			return decoded, e
+		}
+		return fromRecord(record)
+	}
+}
@@ -0,0 +1,82 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+type testCsvRow struct {
+	Minute  int32
+	Ignored string `csv:"-"`
+	Valid   bool
+	Label   string
+	hidden  uint8
+}
+
+func TestCsv(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CsvRecordNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]string, testCsvRow] = CsvRecordNew[testCsvRow]()
+
+		t.Run("valid", func(t *testing.T) {
+			t.Parallel()
+
+			decoded, e := decode([]string{"-42", "true", "hw"})
+			t.Run("no error", assertNil(e))
+			t.Run("minute", assertEq(decoded.Minute, -42))
+			t.Run("valid", assertEq(decoded.Valid, true))
+			t.Run("label", assertEq(decoded.Label, "hw"))
+		})
+
+		t.Run("invalid columns", func(t *testing.T) {
+			t.Parallel()
+
+			_, e := decode([]string{"-42", "true"})
+			t.Run("error", assertEq(errors.Is(e, ErrInvalidRecord), true))
+		})
+
+		t.Run("invalid value", func(t *testing.T) {
+			t.Parallel()
+
+			_, e := decode([]string{"x", "true", "hw"})
+			t.Run("error", assertEq(nil != e, true))
+		})
+	})
+
+	t.Run("CsvNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = CsvNew[testRow](',')
+
+		decoded, e := decode([]byte("599,7,\"a,b\",2.5\n"))
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 599))
+		t.Run("second", assertEq(decoded.Second, 7))
+		t.Run("label", assertEq(decoded.Label, "a,b"))
+		t.Run("score", assertEq(decoded.Score, 2.5))
+
+		t.Run("ConsumerDecodedNew", func(t *testing.T) {
+			t.Parallel()
+
+			var rows []testRow
+			consumer := local.ConsumerDecodedNew(
+				func(row *testRow, _filter *uint8) (stop bool, e error) {
+					rows = append(rows, *row)
+					return
+				},
+				local.DecodeNewPtr(decode),
+				func(_encoded *[]byte, _filter *uint8) (keep bool) { return true },
+			)
+			var encoded []byte = []byte("1,2,x,0.25")
+			var filter uint8
+			_, e := consumer(&encoded, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("single row", assertEq(len(rows), 1))
+		})
+	})
+}
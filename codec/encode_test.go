@@ -0,0 +1,55 @@
+package codec
+
+import (
+	"encoding/binary"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	var samples []testRow = []testRow{
+		{Minute: 42, Second: 59, Label: "hw", Score: 0.5},
+		{Minute: -1, Label: "日本語"},
+		{},
+	}
+	same := func(a, b testRow) bool { return a == b }
+
+	t.Run("json", assertNil(local.RoundTripDecode(
+		JsonEncodeNew[testRow](),
+		JsonNew[testRow](),
+		same,
+		samples,
+	)))
+
+	t.Run("gob", assertNil(local.RoundTripDecode(
+		GobEncodeNew[testRow](),
+		GobNew[testRow](),
+		same,
+		samples,
+	)))
+
+	t.Run("binary", assertNil(local.RoundTripDecode(
+		BinaryEncodeNew[testFixed](binary.LittleEndian),
+		BinaryNew[testFixed](binary.LittleEndian),
+		func(a, b testFixed) bool { return a == b },
+		[]testFixed{
+			{Timestamp: -1, Coarse: 0x3776, Val: [2]uint64{1, 2}},
+			{},
+		},
+	)))
+
+	t.Run("checksum", assertNil(local.RoundTripDecode(
+		local.EncodeCompose(
+			JsonEncodeNew[testRow](),
+			func(payload []byte) ([]byte, error) {
+				return ChecksumCrc32c.AppendTrailer(nil, payload), nil
+			},
+		),
+		TrailerNew(ChecksumCrc32c, JsonNew[testRow]()),
+		same,
+		samples,
+	)))
+}
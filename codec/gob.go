@@ -0,0 +1,32 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// GobNew creates a Decode which decodes gob bytes into a typed value.
+//
+// Each encoded item must be a complete gob stream(type info + value).
+//
+// A gob decoder is created for each item so that the Decode is safe for concurrent use.
+func GobNew[D any]() local.Decode[[]byte, D] {
+	return func(encoded []byte) (decoded D, e error) {
+		var dec *gob.Decoder = gob.NewDecoder(bytes.NewReader(encoded))
+		e = dec.Decode(&decoded)
+		return
+	}
+}
+
+// GobEncodeNew creates an Encode which encodes a value as a complete gob stream.
+//
+// A gob encoder(and a buffer) is created for each value; the Encode is safe for concurrent use.
+func GobEncodeNew[D any]() local.Encode[D, []byte] {
+	return func(decoded D) (encoded []byte, e error) {
+		var buf bytes.Buffer
+		e = gob.NewEncoder(&buf).Encode(decoded)
+		return buf.Bytes(), e
+	}
+}
@@ -0,0 +1,39 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+func TestGob(t *testing.T) {
+	t.Parallel()
+
+	t.Run("GobNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = GobNew[testRow]()
+
+		t.Run("valid", func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			e := gob.NewEncoder(&buf).Encode(testRow{Minute: 3776, Label: "fuji"})
+			t.Run("encoded", assertNil(e))
+
+			decoded, e := decode(buf.Bytes())
+			t.Run("no error", assertNil(e))
+			t.Run("minute", assertEq(decoded.Minute, 3776))
+			t.Run("label", assertEq(decoded.Label, "fuji"))
+		})
+
+		t.Run("invalid", func(t *testing.T) {
+			t.Parallel()
+
+			_, e := decode([]byte{0x01})
+			t.Run("error", assertEq(nil != e, true))
+		})
+	})
+}
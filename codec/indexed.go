@@ -0,0 +1,273 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// ErrUnsorted is returned when sort keys are not ascending.
+var ErrUnsorted = errors.New("unsorted keys")
+
+const (
+	indexedFlagKeys byte = 0x01
+)
+
+// IndexedWriter creates a packed item which has an offset table.
+//
+// Layout: count(uvarint) | flags | keys(8 bytes each, optional) | offsets(4 bytes, count+1) | data
+type IndexedWriter struct {
+	withKeys bool
+	keys     []int64
+	offsets  []uint32
+	data     []byte
+}
+
+// IndexedWriterNew creates an IndexedWriter.
+//
+// # Arguments
+//   - withKeys: Stores an ascending sort key for each child.
+func IndexedWriterNew(withKeys bool) *IndexedWriter {
+	return &IndexedWriter{
+		withKeys: withKeys,
+		offsets:  []uint32{0},
+	}
+}
+
+// Append appends an encoded child.
+//
+// # Arguments
+//   - key: The sort key of the child(ignored if the writer has no keys).
+//   - child: The encoded child.
+func (w *IndexedWriter) Append(key int64, child []byte) error {
+	if w.withKeys {
+		var n int = len(w.keys)
+		if 0 < n && key < w.keys[n-1] {
+			return ErrUnsorted
+		}
+		w.keys = append(w.keys, key)
+	}
+	w.data = append(w.data, child...)
+	w.offsets = append(w.offsets, uint32(len(w.data)))
+	return nil
+}
+
+// AppendTo appends the packed item to dst.
+func (w *IndexedWriter) AppendTo(dst []byte) []byte {
+	var count int = len(w.offsets) - 1
+	dst = binary.AppendUvarint(dst, uint64(count))
+	var flags byte = 0
+	if w.withKeys {
+		flags |= indexedFlagKeys
+	}
+	dst = append(dst, flags)
+	for _, key := range w.keys {
+		dst = binary.BigEndian.AppendUint64(dst, uint64(key))
+	}
+	for _, offset := range w.offsets {
+		dst = binary.BigEndian.AppendUint32(dst, offset)
+	}
+	return append(dst, w.data...)
+}
+
+// IndexedBlock is a parsed packed item which has an offset table.
+type IndexedBlock struct {
+	count   int
+	keys    []byte
+	offsets []byte
+	data    []byte
+}
+
+// Reset parses a packed item(the packed item must not be modified until the next Reset).
+func (b *IndexedBlock) Reset(packed []byte) error {
+	count, rest, e := uvarintRead(packed)
+	if nil != e {
+		return e
+	}
+	if len(rest) < 1 {
+		return ErrInvalidSize
+	}
+	var flags byte = rest[0]
+	rest = rest[1:]
+	// each child needs an offset(4 bytes) at least; avoids overflows below
+	if uint64(len(rest))/4 < count {
+		return ErrInvalidSize
+	}
+	var keySize uint64 = 0
+	if 0 != flags&indexedFlagKeys {
+		keySize = 8 * count
+	}
+	var offsetSize uint64 = 4 * (count + 1)
+	if uint64(len(rest)) < keySize+offsetSize {
+		return ErrInvalidSize
+	}
+	b.count = int(count)
+	b.keys = rest[:keySize]
+	b.offsets = rest[keySize : keySize+offsetSize]
+	b.data = rest[keySize+offsetSize:]
+	var last uint32 = binary.BigEndian.Uint32(b.offsets[4*count:])
+	if uint64(len(b.data)) < uint64(last) {
+		return ErrInvalidSize
+	}
+	return nil
+}
+
+// Len returns the number of children.
+func (b *IndexedBlock) Len() int { return b.count }
+
+// HasKeys returns true if the children have sort keys.
+func (b *IndexedBlock) HasKeys() bool { return 0 < len(b.keys) || 0 == b.count }
+
+// At gets an encoded child by an ordinal.
+func (b *IndexedBlock) At(ordinal int) ([]byte, error) {
+	if ordinal < 0 || b.count <= ordinal {
+		return nil, ErrInvalidRecord
+	}
+	var lbi uint32 = binary.BigEndian.Uint32(b.offsets[4*ordinal:])
+	var ube uint32 = binary.BigEndian.Uint32(b.offsets[4*ordinal+4:])
+	if ube < lbi || uint64(len(b.data)) < uint64(ube) {
+		return nil, ErrInvalidRecord
+	}
+	return b.data[lbi:ube], nil
+}
+
+// Key gets the sort key of a child.
+func (b *IndexedBlock) Key(ordinal int) (int64, error) {
+	if ordinal < 0 || b.count <= ordinal || len(b.keys) < 8*b.count {
+		return 0, ErrInvalidRecord
+	}
+	return int64(binary.BigEndian.Uint64(b.keys[8*ordinal:])), nil
+}
+
+func (b *IndexedBlock) key(ordinal int) int64 {
+	return int64(binary.BigEndian.Uint64(b.keys[8*ordinal:]))
+}
+
+// Range gets ordinals of children whose keys are in [lbi, ube) using binary search.
+//
+// # Return value
+//   - first: The first ordinal.
+//   - last: The ordinal after the last child.
+func (b *IndexedBlock) Range(lbi, ube int64) (first, last int, e error) {
+	if !b.HasKeys() {
+		return 0, 0, ErrUnsupportedType
+	}
+	first = sort.Search(b.count, func(i int) bool { return lbi <= b.key(i) })
+	last = sort.Search(b.count, func(i int) bool { return ube <= b.key(i) })
+	if last < first {
+		last = first
+	}
+	return first, last, nil
+}
+
+func indexedEach[U any](
+	block *IndexedBlock,
+	ordinals func(yield func(ordinal int) (stop bool, e error)) (stop bool, e error),
+	elem local.Decode[[]byte, U],
+	buf *U,
+	each func(unnested *U) (stop bool, e error),
+) (stop bool, e error) {
+	return ordinals(func(ordinal int) (stop bool, e error) {
+		child, e := block.At(ordinal)
+		if nil != e {
+			return true, e
+		}
+		*buf, e = elem(child)
+		if nil != e {
+			return true, e
+		}
+		return each(buf)
+	})
+}
+
+// IndexedRangeNew creates an UnnestFiltered which decodes only children in a key range.
+//
+// The returned UnnestFiltered reuses its buffer and must not be used concurrently.
+//
+// # Arguments
+//   - rangeOf: Gets a key range [lbi, ube) from a filter.
+//   - elem: Decodes a child(the child must not be retained).
+func IndexedRangeNew[U, F any](
+	rangeOf func(filter *F) (lbi, ube int64),
+	elem local.Decode[[]byte, U],
+) local.UnnestFiltered[[]byte, U, F] {
+	var block IndexedBlock
+	return func(
+		packed *[]byte,
+		filter *F,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		e = block.Reset(*packed)
+		if nil != e {
+			return true, e
+		}
+		lbi, ube := rangeOf(filter)
+		first, last, e := block.Range(lbi, ube)
+		if nil != e {
+			return true, e
+		}
+		return indexedEach(
+			&block,
+			func(yield func(int) (bool, error)) (stop bool, e error) {
+				for ordinal := first; ordinal < last; ordinal++ {
+					stop, e = yield(ordinal)
+					if nil != e || stop {
+						return true, e
+					}
+				}
+				return false, nil
+			},
+			elem,
+			buf,
+			each,
+		)
+	}
+}
+
+// IndexedOrdinalsNew creates an UnnestFiltered which decodes only children at ordinals.
+//
+// Ordinals out of range are ignored.
+// The returned UnnestFiltered reuses its buffer and must not be used concurrently.
+//
+// # Arguments
+//   - ordinalsOf: Gets ordinals from a filter.
+//   - elem: Decodes a child(the child must not be retained).
+func IndexedOrdinalsNew[U, F any](
+	ordinalsOf func(filter *F) (ordinals []int),
+	elem local.Decode[[]byte, U],
+) local.UnnestFiltered[[]byte, U, F] {
+	var block IndexedBlock
+	return func(
+		packed *[]byte,
+		filter *F,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		e = block.Reset(*packed)
+		if nil != e {
+			return true, e
+		}
+		var ordinals []int = ordinalsOf(filter)
+		return indexedEach(
+			&block,
+			func(yield func(int) (bool, error)) (stop bool, e error) {
+				for _, ordinal := range ordinals {
+					if ordinal < 0 || block.count <= ordinal {
+						continue
+					}
+					stop, e = yield(ordinal)
+					if nil != e || stop {
+						return true, e
+					}
+				}
+				return false, nil
+			},
+			elem,
+			buf,
+			each,
+		)
+	}
+}
@@ -0,0 +1,133 @@
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+type testIndexedFilter struct {
+	lbi      int64
+	ube      int64
+	ordinals []int
+}
+
+func testIndexedBlock(t *testing.T, withKeys bool) []byte {
+	var w *IndexedWriter = IndexedWriterNew(withKeys)
+	for second := int64(0); second < 60; second += 2 {
+		var child []byte = []byte(`{"second":` + strconv.FormatInt(second, 10) + `}`)
+		t.Run("append", assertNil(w.Append(second, child)))
+	}
+	return w.AppendTo(nil)
+}
+
+func TestIndexed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("IndexedWriter", func(t *testing.T) {
+		t.Parallel()
+
+		var w *IndexedWriter = IndexedWriterNew(true)
+		t.Run("first", assertNil(w.Append(3, nil)))
+		t.Run("unsorted", assertEq(errors.Is(w.Append(2, nil), ErrUnsorted), true))
+	})
+
+	t.Run("IndexedBlock", func(t *testing.T) {
+		t.Parallel()
+
+		var block IndexedBlock
+		t.Run("parsed", assertNil(block.Reset(testIndexedBlock(t, true))))
+		t.Run("30 children", assertEq(block.Len(), 30))
+
+		child, e := block.At(29)
+		t.Run("no error", assertNil(e))
+		t.Run("last child", assertEq(string(child), `{"second":58}`))
+
+		key, e := block.Key(10)
+		t.Run("no error", assertNil(e))
+		t.Run("key", assertEq(key, 20))
+
+		first, last, e := block.Range(5, 11)
+		t.Run("no error", assertNil(e))
+		t.Run("first", assertEq(first, 3))
+		t.Run("last", assertEq(last, 6))
+
+		first, last, _ = block.Range(100, 200)
+		t.Run("empty range", assertEq(first, last))
+
+		_, e = block.At(30)
+		t.Run("out of range", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		var noKeys IndexedBlock
+		t.Run("parsed", assertNil(noKeys.Reset(testIndexedBlock(t, false))))
+		_, _, e = noKeys.Range(0, 1)
+		t.Run("no keys", assertEq(errors.Is(e, ErrUnsupportedType), true))
+
+		// count=1<<62 with a single offset
+		var corrupt []byte = binary.AppendUvarint(nil, 1<<62)
+		corrupt = append(corrupt, indexedFlagKeys)
+		corrupt = append(corrupt, 0, 0, 0, 0)
+		var broken IndexedBlock
+		e = broken.Reset(corrupt)
+		t.Run("corrupted count", assertEq(errors.Is(e, ErrInvalidSize), true))
+	})
+
+	t.Run("IndexedRangeNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decoded int = 0
+		var unnest local.UnnestFiltered[[]byte, testRow, testIndexedFilter] = IndexedRangeNew(
+			func(f *testIndexedFilter) (int64, int64) { return f.lbi, f.ube },
+			func(child []byte) (testRow, error) {
+				decoded += 1
+				return JsonNew[testRow]()(child)
+			},
+		)
+
+		var rows []testRow
+		run := local.PipelineSliceNew(
+			local.PipeUnnestFiltered(
+				local.PipelineNew[[]byte, testIndexedFilter](),
+				unnest,
+			).Filter(func(r *testRow, _ *testIndexedFilter) bool { return 0 != r.Second%4 }),
+			func(r *testRow, _ *testIndexedFilter) (stop bool, e error) {
+				rows = append(rows, *r)
+				return
+			},
+		)
+
+		var filter testIndexedFilter = testIndexedFilter{lbi: 10, ube: 21}
+		e := run(context.Background(), [][]byte{testIndexedBlock(t, true)}, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("6 children decoded", assertEq(decoded, 6))
+		t.Run("3 rows", assertEq(len(rows), 3))
+		t.Run("first", assertEq(rows[0].Second, 10))
+		t.Run("last", assertEq(rows[2].Second, 18))
+	})
+
+	t.Run("IndexedOrdinalsNew", func(t *testing.T) {
+		t.Parallel()
+
+		var unnest local.UnnestFiltered[[]byte, testRow, testIndexedFilter] = IndexedOrdinalsNew(
+			func(f *testIndexedFilter) []int { return f.ordinals },
+			JsonNew[testRow](),
+		)
+
+		var packed []byte = testIndexedBlock(t, false)
+		var filter testIndexedFilter = testIndexedFilter{ordinals: []int{29, -1, 0, 99, 1}}
+		var rows []testRow
+		var buf testRow
+		_, e := unnest(&packed, &filter, &buf, func(r *testRow) (stop bool, e error) {
+			rows = append(rows, *r)
+			return 2 == len(rows), nil
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("2 rows", assertEq(len(rows), 2))
+		t.Run("first", assertEq(rows[0].Second, 58))
+		t.Run("second", assertEq(rows[1].Second, 0))
+	})
+}
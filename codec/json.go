@@ -0,0 +1,24 @@
+package codec
+
+import (
+	"encoding/json"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// JsonNew creates a Decode which decodes json bytes into a typed value.
+//
+// The Decode keeps no state and is safe for concurrent use.
+func JsonNew[D any]() local.Decode[[]byte, D] {
+	return func(encoded []byte) (decoded D, e error) {
+		e = json.Unmarshal(encoded, &decoded)
+		return
+	}
+}
+
+// JsonEncodeNew creates an Encode which encodes a value as json bytes.
+//
+// json.Marshal keeps no state so that the Encode is safe for concurrent use.
+func JsonEncodeNew[D any]() local.Encode[D, []byte] {
+	return func(decoded D) (encoded []byte, e error) { return json.Marshal(decoded) }
+}
@@ -0,0 +1,55 @@
+package codec
+
+import (
+	"context"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+func TestJson(t *testing.T) {
+	t.Parallel()
+
+	t.Run("JsonNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = JsonNew[testRow]()
+
+		t.Run("valid", func(t *testing.T) {
+			t.Parallel()
+
+			decoded, e := decode([]byte(`{"minute":42,"second":59,"label":"hw","score":0.5}`))
+			t.Run("no error", assertNil(e))
+			t.Run("minute", assertEq(decoded.Minute, 42))
+			t.Run("second", assertEq(decoded.Second, 59))
+			t.Run("label", assertEq(decoded.Label, "hw"))
+			t.Run("score", assertEq(decoded.Score, 0.5))
+		})
+
+		t.Run("invalid", func(t *testing.T) {
+			t.Parallel()
+
+			_, e := decode([]byte(`{"minute":`))
+			t.Run("error", assertEq(nil != e, true))
+		})
+
+		t.Run("GetByKeyNewDecoded", func(t *testing.T) {
+			t.Parallel()
+
+			var buf []byte
+			getByKey := local.GetByKeyNewDecoded(
+				func(_ context.Context, _con uint8, key int32, encoded *[]byte) (bool, error) {
+					*encoded = append((*encoded)[:0], `{"minute":634}`...)
+					return true, nil
+				},
+				local.DecodeNewPtr(decode),
+				&buf,
+			)
+			var row testRow
+			got, e := getByKey(context.Background(), 0, 634, &row)
+			t.Run("no error", assertNil(e))
+			t.Run("got", assertEq(got, true))
+			t.Run("minute", assertEq(row.Minute, 634))
+		})
+	})
+}
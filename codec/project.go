@@ -0,0 +1,49 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// JsonProjectNew creates a Projection which decodes only the fields declared by R.
+//
+// An encoded item is decoded by json.Unmarshal so that keys are matched in the same way
+// as a full decode(the last one wins for a duplicated key);
+// values of undeclared keys are skipped without being stored.
+// The Projection keeps no state and is safe for concurrent use.
+func JsonProjectNew[R any]() local.Projection[[]byte, R] {
+	return func(encoded *[]byte) (projected R, e error) {
+		var trimmed []byte = bytes.TrimLeft(*encoded, " \t\r\n")
+		if len(trimmed) < 1 || '{' != trimmed[0] {
+			return projected, ErrInvalidRecord
+		}
+		e = json.Unmarshal(trimmed, &projected)
+		return
+	}
+}
+
+// BinaryAtNew creates a Projection which reads a fixed-size value at an offset.
+//
+// Only the bytes at the offset are read; the Projection is safe for concurrent use.
+//
+// # Arguments
+//   - order: binary.BigEndian or binary.LittleEndian.
+//   - offset: The offset of the value in an encoded item.
+func BinaryAtNew[R any](order binary.ByteOrder, offset int) local.Projection[[]byte, R] {
+	var decode local.Decode[[]byte, R] = BinaryNew[R](order)
+	var empty R
+	var size int = binary.Size(&empty)
+	return func(encoded *[]byte) (projected R, e error) {
+		if size < 0 {
+			return projected, ErrUnsupportedType
+		}
+		var ube int = offset + size
+		if len(*encoded) < ube {
+			return projected, ErrInvalidSize
+		}
+		return decode((*encoded)[offset:ube])
+	}
+}
@@ -0,0 +1,225 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+type testProjected struct {
+	Minute int32 `json:"minute"`
+	Label  string
+}
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("JsonProjectNew", func(t *testing.T) {
+		t.Parallel()
+
+		var project local.Projection[[]byte, testProjected] = JsonProjectNew[testProjected]()
+
+		t.Run("declared fields", func(t *testing.T) {
+			t.Parallel()
+
+			var encoded []byte = []byte(
+				`{"second":7,"nested":{"minute":1},"minute":42,"Label":"hw","score":[1,2]}`,
+			)
+			projected, e := project(&encoded)
+			t.Run("no error", assertNil(e))
+			t.Run("minute", assertEq(projected.Minute, 42))
+			t.Run("label", assertEq(projected.Label, "hw"))
+		})
+
+		t.Run("truncated", func(t *testing.T) {
+			t.Parallel()
+
+			var encoded []byte = []byte(`{"minute":42,"Label":"hw","broken":`)
+			_, e := project(&encoded)
+			t.Run("error", assertEq(nil != e, true))
+		})
+
+		t.Run("case-insensitive keys", func(t *testing.T) {
+			t.Parallel()
+
+			var encoded []byte = []byte(`{"Minute":5,"LABEL":"hw"}`)
+			projected, e := project(&encoded)
+			t.Run("no error", assertNil(e))
+			t.Run("minute", assertEq(projected.Minute, 5))
+			t.Run("label", assertEq(projected.Label, "hw"))
+
+			var full testProjected
+			e = json.Unmarshal(encoded, &full)
+			t.Run("same as unmarshal", assertEq(full, projected))
+		})
+
+		t.Run("duplicated keys", func(t *testing.T) {
+			t.Parallel()
+
+			var encoded []byte = []byte(`{"minute":1,"minute":2,"Label":"hw"}`)
+			projected, e := project(&encoded)
+			t.Run("no error", assertNil(e))
+			t.Run("last minute", assertEq(projected.Minute, 2))
+			t.Run("label", assertEq(projected.Label, "hw"))
+
+			var full testProjected
+			e = json.Unmarshal(encoded, &full)
+			t.Run("same as unmarshal", assertEq(full, projected))
+
+			encoded = []byte(`{"minute":2,"minute":1}`)
+			projected, e = project(&encoded)
+			t.Run("no error", assertNil(e))
+			t.Run("last minute", assertEq(projected.Minute, 1))
+		})
+
+		t.Run("not an object", func(t *testing.T) {
+			t.Parallel()
+
+			var encoded []byte = []byte(`[1,2]`)
+			_, e := project(&encoded)
+			t.Run("error", assertEq(errors.Is(e, ErrInvalidRecord), true))
+		})
+	})
+
+	t.Run("BinaryAtNew", func(t *testing.T) {
+		t.Parallel()
+
+		var project local.Projection[[]byte, uint16] = BinaryAtNew[uint16](binary.BigEndian, 8)
+
+		var encoded []byte = make([]byte, 26)
+		binary.BigEndian.PutUint16(encoded[8:10], 0x3776)
+		projected, e := project(&encoded)
+		t.Run("no error", assertNil(e))
+		t.Run("coarse", assertEq(projected, 0x3776))
+
+		var short []byte = make([]byte, 9)
+		_, e = project(&short)
+		t.Run("too short", assertEq(errors.Is(e, ErrInvalidSize), true))
+	})
+}
+
+type testWide struct {
+	Minute int32             `json:"minute"`
+	F01    string            `json:"f01"`
+	F02    string            `json:"f02"`
+	F03    string            `json:"f03"`
+	F04    string            `json:"f04"`
+	F05    string            `json:"f05"`
+	F06    []int             `json:"f06"`
+	F07    []int             `json:"f07"`
+	F08    []int             `json:"f08"`
+	F09    []int             `json:"f09"`
+	F10    []int             `json:"f10"`
+	F11    map[string]string `json:"f11"`
+	F12    map[string]string `json:"f12"`
+	F13    map[string]string `json:"f13"`
+	F14    float64           `json:"f14"`
+	F15    float64           `json:"f15"`
+	F16    bool              `json:"f16"`
+	F17    bool              `json:"f17"`
+	F18    string            `json:"f18"`
+	F19    string            `json:"f19"`
+}
+
+type testMinute struct {
+	Minute int32 `json:"minute"`
+}
+
+func testWideRows(n int) [][]byte {
+	var rows [][]byte = make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		var row testWide = testWide{
+			Minute: int32(i % 60),
+			F01:    "the quick brown fox",
+			F02:    "jumps over the lazy dog",
+			F03:    "lorem ipsum dolor sit amet",
+			F04:    "consectetur adipiscing elit",
+			F05:    "sed do eiusmod tempor",
+			F06:    []int{1, 2, 3, 4, 5, 6, 7, 8},
+			F07:    []int{9, 10, 11, 12},
+			F08:    []int{13, 14, 15, 16},
+			F09:    []int{17, 18, 19, 20},
+			F10:    []int{21, 22, 23, 24},
+			F11:    map[string]string{"a": "1", "b": "2"},
+			F12:    map[string]string{"c": "3", "d": "4"},
+			F13:    map[string]string{"e": "5", "f": "6"},
+			F14:    3.14,
+			F15:    2.71,
+			F16:    true,
+			F18:    "incididunt ut labore",
+			F19:    "et dolore magna aliqua",
+		}
+		encoded, _ := json.Marshal(row)
+		rows = append(rows, encoded)
+	}
+	return rows
+}
+
+func BenchmarkProject(b *testing.B) {
+	var rows [][]byte = testWideRows(600)
+	var filter int32 = 42
+
+	b.Run("JsonNew", func(b *testing.B) {
+		var decode local.Decode[[]byte, testWide] = JsonNew[testWide]()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = decode(rows[i%len(rows)])
+		}
+	})
+
+	b.Run("JsonProjectNew", func(b *testing.B) {
+		var project local.Projection[[]byte, testMinute] = JsonProjectNew[testMinute]()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = project(&rows[i%len(rows)])
+		}
+	})
+
+	var kept int
+	var consumer local.IterConsumerFiltered[testWide, int32] = func(
+		_ *testWide,
+		_ *int32,
+	) (stop bool, e error) {
+		kept += 1
+		return false, nil
+	}
+	var decode local.Decode[[]byte, testWide] = JsonNew[testWide]()
+	var decoder func(*[]byte) (testWide, error) = func(encoded *[]byte) (testWide, error) {
+		return decode(*encoded)
+	}
+
+	b.Run("ConsumerDecodedNew", func(b *testing.B) {
+		var consume local.IterConsumerFiltered[[]byte, int32] = local.ConsumerDecodedNew(
+			func(decoded *testWide, filter *int32) (stop bool, e error) {
+				if decoded.Minute != *filter {
+					return false, nil
+				}
+				return consumer(decoded, filter)
+			},
+			decoder,
+			func(_ *[]byte, _ *int32) (keep bool) { return true },
+		)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = consume(&rows[i%len(rows)], &filter)
+		}
+	})
+
+	b.Run("ConsumerLazyNew", func(b *testing.B) {
+		var consume local.IterConsumerFiltered[[]byte, int32] = local.ConsumerLazyNew(
+			consumer,
+			JsonProjectNew[testMinute](),
+			func(projected *testMinute, filter *int32) (keep bool) {
+				return projected.Minute == *filter
+			},
+			decoder,
+		)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = consume(&rows[i%len(rows)], &filter)
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package codec
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+const (
+	testRaceRows    int = 32
+	testRaceWorkers int = 8
+	testRaceRuns    int = 16
+)
+
+// testRaceCheck runs a shared closure from many goroutines(use go test -race).
+//
+// # Arguments
+//   - want: The sum of values added by a run.
+//   - shared: Creates a closure once; the closure is used by all goroutines.
+func testRaceCheck(want int, shared func(add func(v int)) (run func() error)) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		var total int64 = 0
+		var run func() error = shared(func(v int) { atomic.AddInt64(&total, int64(v)) })
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		var e error
+		wg.Add(testRaceWorkers)
+		for w := 0; w < testRaceWorkers; w++ {
+			go func() {
+				defer wg.Done()
+				for r := 0; r < testRaceRuns; r++ {
+					err := run()
+					mu.Lock()
+					if nil != err && nil == e {
+						e = err
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		t.Run("no error", assertNil(e))
+		t.Run("all values", assertEq(
+			atomic.LoadInt64(&total),
+			int64(want*testRaceWorkers*testRaceRuns),
+		))
+	}
+}
+
+// testRaceEach decodes all encoded items using a shared Decode.
+func testRaceEach[E, D any](
+	encoded []E,
+	decode local.Decode[E, D],
+	minute func(decoded D) int32,
+	add func(v int),
+) func() error {
+	return func() error {
+		for _, item := range encoded {
+			decoded, e := decode(item)
+			if nil != e {
+				return e
+			}
+			add(int(minute(decoded)))
+		}
+		return nil
+	}
+}
+
+// testRaceUnnest unnests a packed item using a shared UnnestEach.
+func testRaceUnnest(
+	packed []byte,
+	unnest local.UnnestEach[[]byte, testRow],
+	add func(v int),
+) func() error {
+	return func() error {
+		var buf testRow
+		_, e := unnest(&packed, &buf, func(row *testRow) (stop bool, e error) {
+			add(int(row.Minute))
+			return false, nil
+		})
+		return e
+	}
+}
+
+func TestRace(t *testing.T) {
+	t.Parallel()
+
+	var rows []testRow
+	var want int = 0
+	for minute := 0; minute < testRaceRows; minute++ {
+		rows = append(rows, testRow{Minute: int32(minute), Label: strconv.Itoa(minute)})
+		want += minute
+	}
+
+	encodeAll := func(encode local.Encode[testRow, []byte]) (encoded [][]byte) {
+		for _, row := range rows {
+			item, e := encode(row)
+			t.Run("encoded", assertNil(e))
+			encoded = append(encoded, item)
+		}
+		return
+	}
+
+	var jsons [][]byte = encodeAll(JsonEncodeNew[testRow]())
+	var gobs [][]byte = encodeAll(GobEncodeNew[testRow]())
+
+	// odd minutes use the old layout.
+	var encodeV1 local.Encode[testRowV1, []byte] = JsonEncodeNew[testRowV1]()
+	var versioned [][]byte
+	for ix, item := range jsons {
+		if 0 == ix%2 {
+			versioned = append(versioned, append([]byte{2}, item...))
+			continue
+		}
+		old, e := encodeV1(testRowV1{Minute: rows[ix].Minute})
+		t.Run("encoded", assertNil(e))
+		versioned = append(versioned, append([]byte{1}, old...))
+	}
+
+	var w *ColumnarWriter = ColumnarWriterNew(testRaceRows)
+	var minutes []int64
+	var labels []string
+	for _, row := range rows {
+		minutes = append(minutes, int64(row.Minute))
+		labels = append(labels, row.Label)
+	}
+	t.Run("minutes", assertNil(w.Int64s(ColumnPlain, minutes)))
+	t.Run("labels", assertNil(w.Strings(ColumnDict, labels)))
+	var columnar []byte = w.AppendTo(nil)
+	minuteOf := func(row testRow) int32 { return row.Minute }
+
+	t.Run("JsonNew", testRaceCheck(want, func(add func(int)) func() error {
+		return testRaceEach(jsons, JsonNew[testRow](), minuteOf, add)
+	}))
+
+	t.Run("GobNew", testRaceCheck(want, func(add func(int)) func() error {
+		return testRaceEach(gobs, GobNew[testRow](), minuteOf, add)
+	}))
+
+	t.Run("BinaryNew", testRaceCheck(want, func(add func(int)) func() error {
+		var encode local.Encode[int32, []byte] = BinaryEncodeNew[int32](binary.BigEndian)
+		var encoded [][]byte
+		for _, row := range rows {
+			item, _ := encode(row.Minute)
+			encoded = append(encoded, item)
+		}
+		return testRaceEach(
+			encoded,
+			BinaryNew[int32](binary.BigEndian),
+			func(minute int32) int32 { return minute },
+			add,
+		)
+	}))
+
+	t.Run("CsvNew", testRaceCheck(want, func(add func(int)) func() error {
+		var encoded [][]byte
+		for _, row := range rows {
+			encoded = append(encoded, []byte(strconv.Itoa(int(row.Minute))+",0,"+row.Label+",0\n"))
+		}
+		return testRaceEach(encoded, CsvNew[testRow](','), minuteOf, add)
+	}))
+
+	t.Run("Versioned", testRaceCheck(want, func(add func(int)) func() error {
+		var decode local.Decode[[]byte, testRow] = VersionedNew[[]byte, testRow](
+			VersionLeadingByte,
+		).WithVersion(
+			1,
+			MigrateNew(JsonNew[testRowV1](), func(old testRowV1) (testRow, error) {
+				return testRow{Minute: old.Minute}, nil
+			}),
+		).WithVersion(2, JsonNew[testRow]()).Decode()
+		return testRaceEach(versioned, decode, minuteOf, add)
+	}))
+
+	t.Run("OpenNew", testRaceCheck(want, func(add func(int)) func() error {
+		var bucket local.Bucket = local.BucketNew("race")
+		seal := SealNew(testKeys, 2, rand.Reader)
+		var decode local.Decode[Sealed, testRow] = OpenNew(testKeys, JsonNew[testRow]())
+		return func() error {
+			for ix, item := range jsons {
+				var key []byte = []byte(strconv.Itoa(ix))
+				sealed, e := seal(bucket, key, item)
+				if nil != e {
+					return e
+				}
+				decoded, e := decode(Sealed{Bucket: bucket, Key: key, Val: sealed})
+				if nil != e {
+					return e
+				}
+				add(int(decoded.Minute))
+			}
+			return nil
+		}
+	}))
+
+	t.Run("TrailerNew", testRaceCheck(want, func(add func(int)) func() error {
+		var encoded [][]byte
+		for _, item := range jsons {
+			encoded = append(encoded, ChecksumCrc32c.AppendTrailer(nil, item))
+		}
+		return testRaceEach(encoded, TrailerNew(ChecksumCrc32c, JsonNew[testRow]()), minuteOf, add)
+	}))
+
+	t.Run("PrefixNew", testRaceCheck(want, func(add func(int)) func() error {
+		var encoded [][]byte
+		for _, item := range jsons {
+			encoded = append(encoded, ChecksumXxh64.AppendPrefix(nil, item))
+		}
+		return testRaceEach(encoded, PrefixNew(ChecksumXxh64, JsonNew[testRow]()), minuteOf, add)
+	}))
+
+	t.Run("SideNew", testRaceCheck(want, func(add func(int)) func() error {
+		var encoded []Checksummed
+		for _, item := range jsons {
+			encoded = append(encoded, Checksummed{Payload: item, Sum: Crc32c(item)})
+		}
+		return testRaceEach(encoded, SideNew(ChecksumCrc32c, JsonNew[testRow]()), minuteOf, add)
+	}))
+
+	t.Run("JsonProjectNew", testRaceCheck(want, func(add func(int)) func() error {
+		var project local.Projection[[]byte, testProjected] = JsonProjectNew[testProjected]()
+		return func() error {
+			for ix := range jsons {
+				projected, e := project(&jsons[ix])
+				if nil != e {
+					return e
+				}
+				add(int(projected.Minute))
+			}
+			return nil
+		}
+	}))
+
+	t.Run("BinaryAtNew", testRaceCheck(want, func(add func(int)) func() error {
+		var project local.Projection[[]byte, int32] = BinaryAtNew[int32](binary.LittleEndian, 2)
+		return func() error {
+			for _, row := range rows {
+				var encoded []byte = binary.LittleEndian.AppendUint32([]byte{0, 0}, uint32(row.Minute))
+				projected, e := project(&encoded)
+				if nil != e {
+					return e
+				}
+				add(int(projected))
+			}
+			return nil
+		}
+	}))
+
+	t.Run("JsonArrayNew", testRaceCheck(want, func(add func(int)) func() error {
+		var packed []byte = []byte("[")
+		for ix, item := range jsons {
+			if 0 < ix {
+				packed = append(packed, ',')
+			}
+			packed = append(packed, item...)
+		}
+		packed = append(packed, ']')
+		return testRaceUnnest(packed, JsonArrayNew(JsonNew[testRow]()), add)
+	}))
+
+	t.Run("FramesNew", testRaceCheck(want, func(add func(int)) func() error {
+		var packed []byte
+		for _, item := range jsons {
+			packed = AppendFrame(packed, item)
+		}
+		return testRaceUnnest(packed, FramesNew(JsonNew[testRow]()), add)
+	}))
+
+	t.Run("NdjsonNew", testRaceCheck(want, func(add func(int)) func() error {
+		var packed []byte
+		for _, item := range jsons {
+			packed = append(append(packed, item...), '\n')
+		}
+		return testRaceUnnest(packed, NdjsonNew(JsonNew[testRow]()), add)
+	}))
+
+	t.Run("ColumnInt64Where", testRaceCheck(want, func(add func(int)) func() error {
+		var predicates []ColumnPredicate[testColumnarFilter] = []ColumnPredicate[testColumnarFilter]{
+			ColumnInt64Where(0, func(v int64, f *testColumnarFilter) bool { return f.minute <= v }),
+			ColumnStringWhere(1, func(v string, f *testColumnarFilter) bool { return f.label != v }),
+		}
+		return func() error {
+			// a block is used by a single goroutine.
+			var block ColumnarBlock
+			e := block.Reset(columnar)
+			if nil != e {
+				return e
+			}
+			var selected []int
+			for row := 0; row < testRaceRows; row++ {
+				selected = append(selected, row)
+			}
+			var filter testColumnarFilter = testColumnarFilter{minute: 0, label: "none"}
+			for _, predicate := range predicates {
+				selected, e = predicate(&block, selected, &filter)
+				if nil != e {
+					return e
+				}
+			}
+			for _, row := range selected {
+				minute, e := block.Int64(0, row)
+				if nil != e {
+					return e
+				}
+				add(int(minute))
+			}
+			return nil
+		}
+	}))
+}
@@ -0,0 +1,174 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// JsonArrayNew creates an UnnestEach which streams elements of a json array.
+//
+// Elements are passed to the element decoder one by one(the array is not materialized).
+// Each element is parsed twice: once to find its end, then by elem;
+// use JsonArrayOfNew to decode elements directly.
+//
+// The UnnestEach is safe for concurrent use if elem is.
+//
+// # Arguments
+//   - elem: Decodes a raw json element(the element must not be retained; it is reused).
+func JsonArrayNew[U any](elem local.Decode[[]byte, U]) local.UnnestEach[[]byte, U] {
+	return func(
+		packed *[]byte,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		var dec *json.Decoder = json.NewDecoder(bytes.NewReader(*packed))
+		tok, e := dec.Token()
+		if nil != e {
+			return true, e
+		}
+		if json.Delim('[') != tok {
+			return true, ErrInvalidRecord
+		}
+		var raw json.RawMessage
+		for dec.More() {
+			raw = raw[:0]
+			e = dec.Decode(&raw)
+			if nil != e {
+				return true, e
+			}
+			*buf, e = elem(raw)
+			if nil != e {
+				return true, e
+			}
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		_, e = dec.Token()
+		return false, e
+	}
+}
+
+// JsonArrayOfNew creates an UnnestEach which decodes elements of a json array directly.
+//
+// Each element is parsed once and decoded into a zeroed buf by encoding/json.
+// The UnnestEach keeps no state and is safe for concurrent use.
+func JsonArrayOfNew[U any]() local.UnnestEach[[]byte, U] {
+	return func(
+		packed *[]byte,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		var dec *json.Decoder = json.NewDecoder(bytes.NewReader(*packed))
+		tok, e := dec.Token()
+		if nil != e {
+			return true, e
+		}
+		if json.Delim('[') != tok {
+			return true, ErrInvalidRecord
+		}
+		var empty U
+		for dec.More() {
+			*buf = empty
+			e = dec.Decode(buf)
+			if nil != e {
+				return true, e
+			}
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		_, e = dec.Token()
+		return false, e
+	}
+}
+
+// AppendFrame appends a uvarint length-prefixed frame to dst.
+func AppendFrame(dst []byte, frame []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(frame)))
+	return append(dst, frame...)
+}
+
+// FramesNew creates an UnnestEach which uses uvarint length-prefixed frames.
+//
+// Frames are sliced from the packed item without copying;
+// the UnnestEach is safe for concurrent use if elem is.
+//
+// # Arguments
+//   - elem: Decodes a frame(the frame must not be retained).
+func FramesNew[U any](elem local.Decode[[]byte, U]) local.UnnestEach[[]byte, U] {
+	return func(
+		packed *[]byte,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		var rest []byte = *packed
+		for 0 < len(rest) {
+			size, n := binary.Uvarint(rest)
+			if n <= 0 {
+				return true, ErrInvalidSize
+			}
+			rest = rest[n:]
+			if uint64(len(rest)) < size {
+				return true, ErrInvalidSize
+			}
+			*buf, e = elem(rest[:size])
+			if nil != e {
+				return true, e
+			}
+			rest = rest[size:]
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		return false, nil
+	}
+}
+
+// NdjsonNew creates an UnnestEach which uses newline-delimited records.
+//
+// Empty lines are skipped and a trailing '\r' is removed.
+//
+// Lines are not copied and nothing is kept between calls;
+// the UnnestEach is safe for concurrent use if elem is.
+//
+// # Arguments
+//   - elem: Decodes a line(e.g, JsonNew; the line must not be retained).
+func NdjsonNew[U any](elem local.Decode[[]byte, U]) local.UnnestEach[[]byte, U] {
+	return func(
+		packed *[]byte,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		var rest []byte = *packed
+		for 0 < len(rest) {
+			var line []byte = rest
+			var ix int = bytes.IndexByte(rest, '\n')
+			if ix < 0 {
+				rest = nil
+			} else {
+				line = rest[:ix]
+				rest = rest[ix+1:]
+			}
+			line = bytes.TrimSuffix(line, []byte{'\r'})
+			if 0 == len(line) {
+				continue
+			}
+			*buf, e = elem(line)
+			if nil != e {
+				return true, e
+			}
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		return false, nil
+	}
+}
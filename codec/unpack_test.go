@@ -0,0 +1,144 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+func testCollect[U any](
+	unnest local.UnnestEach[[]byte, U],
+	packed []byte,
+	limit int,
+) (items []U, e error) {
+	var buf U
+	_, e = unnest(&packed, &buf, func(item *U) (stop bool, e error) {
+		items = append(items, *item)
+		return limit == len(items), nil
+	})
+	return
+}
+
+func TestUnpack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("JsonArrayNew", func(t *testing.T) {
+		t.Parallel()
+
+		var unnest local.UnnestEach[[]byte, testRow] = JsonArrayNew(JsonNew[testRow]())
+
+		t.Run("all", func(t *testing.T) {
+			t.Parallel()
+
+			rows, e := testCollect(
+				unnest,
+				[]byte(`[{"second":1},{"second":2,"label":"x"},{"second":3}]`),
+				-1,
+			)
+			t.Run("no error", assertNil(e))
+			t.Run("3 rows", assertEq(len(rows), 3))
+			t.Run("label", assertEq(rows[1].Label, "x"))
+			t.Run("second", assertEq(rows[2].Second, 3))
+		})
+
+		t.Run("stop before broken tail", func(t *testing.T) {
+			t.Parallel()
+
+			rows, e := testCollect(unnest, []byte(`[{"second":1},{"second":2},{"sec`), 2)
+			t.Run("no error", assertNil(e))
+			t.Run("2 rows", assertEq(len(rows), 2))
+		})
+
+		t.Run("not an array", func(t *testing.T) {
+			t.Parallel()
+
+			_, e := testCollect(unnest, []byte(`{}`), -1)
+			t.Run("error", assertEq(errors.Is(e, ErrInvalidRecord), true))
+		})
+
+		t.Run("Unpack", func(t *testing.T) {
+			t.Parallel()
+
+			var unpack local.Unpack[[]byte, testRow] = unnest.ToUnnest().ToUnpack()
+			getUnpacked := unpack.NewAll(func(_ context.Context, _b local.Bucket) (
+				[][]byte,
+				error,
+			) {
+				return [][]byte{[]byte(`[{"minute":1},{"minute":2}]`), []byte(`[]`)}, nil
+			})
+			rows, e := getUnpacked(context.Background(), local.BucketNew(""))
+			t.Run("no error", assertNil(e))
+			t.Run("2 rows", assertEq(len(rows), 2))
+		})
+	})
+
+	t.Run("JsonArrayOfNew", func(t *testing.T) {
+		t.Parallel()
+
+		var unnest local.UnnestEach[[]byte, testRow] = JsonArrayOfNew[testRow]()
+
+		rows, e := testCollect(
+			unnest,
+			[]byte(`[{"second":1},{"second":2,"label":"x"},{"second":3}]`),
+			-1,
+		)
+		t.Run("no error", assertNil(e))
+		t.Run("3 rows", assertEq(len(rows), 3))
+		t.Run("label", assertEq(rows[1].Label, "x"))
+		t.Run("zeroed buf", assertEq(rows[2].Label, ""))
+		t.Run("second", assertEq(rows[2].Second, 3))
+
+		rows, e = testCollect(unnest, []byte(`[{"second":1},{"second":2},{"sec`), 2)
+		t.Run("stop before broken tail", assertNil(e))
+		t.Run("2 rows", assertEq(len(rows), 2))
+
+		_, e = testCollect(unnest, []byte(`{}`), -1)
+		t.Run("not an array", assertEq(errors.Is(e, ErrInvalidRecord), true))
+
+		_, e = testCollect(unnest, []byte(`[{"second":1},{"second":"x"}]`), -1)
+		t.Run("element error", assertEq(nil != e, true))
+	})
+
+	t.Run("FramesNew", func(t *testing.T) {
+		t.Parallel()
+
+		var unnest local.UnnestEach[[]byte, string] = FramesNew(
+			func(frame []byte) (string, error) { return string(frame), nil },
+		)
+
+		var packed []byte
+		packed = AppendFrame(packed, []byte("hello"))
+		packed = AppendFrame(packed, nil)
+		packed = AppendFrame(packed, make([]byte, 300))
+
+		items, e := testCollect(unnest, packed, -1)
+		t.Run("no error", assertNil(e))
+		t.Run("3 items", assertEq(len(items), 3))
+		t.Run("item 0", assertEq(items[0], "hello"))
+		t.Run("item 1", assertEq(items[1], ""))
+		t.Run("item 2", assertEq(len(items[2]), 300))
+
+		_, e = testCollect(unnest, packed[:len(packed)-1], -1)
+		t.Run("truncated", assertEq(errors.Is(e, ErrInvalidSize), true))
+	})
+
+	t.Run("NdjsonNew", func(t *testing.T) {
+		t.Parallel()
+
+		var unnest local.UnnestEach[[]byte, testRow] = NdjsonNew(JsonNew[testRow]())
+
+		rows, e := testCollect(
+			unnest,
+			[]byte("{\"minute\":1}\r\n\n{\"minute\":2}\n{\"minute\":3}"),
+			-1,
+		)
+		t.Run("no error", assertNil(e))
+		t.Run("3 rows", assertEq(len(rows), 3))
+		t.Run("last", assertEq(rows[2].Minute, 3))
+
+		_, e = testCollect(unnest, []byte("{\"minute\":1}\n{"), -1)
+		t.Run("invalid line", assertEq(nil != e, true))
+	})
+}
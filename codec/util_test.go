@@ -0,0 +1,30 @@
+package codec
+
+import (
+	"testing"
+)
+
+func assertNil(e any) func(*testing.T) {
+	return func(t *testing.T) {
+		if nil != e {
+			t.Fatalf("Must be nil: %v\n", e)
+		}
+	}
+}
+
+func assertEq[T comparable](a, b T) func(*testing.T) {
+	return func(t *testing.T) {
+		if a != b {
+			t.Errorf("a != b\n")
+			t.Errorf("a: %v\n", a)
+			t.Fatalf("b: %v\n", b)
+		}
+	}
+}
+
+type testRow struct {
+	Minute int32   `json:"minute"`
+	Second uint8   `json:"second"`
+	Label  string  `json:"label"`
+	Score  float64 `json:"score"`
+}
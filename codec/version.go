@@ -0,0 +1,112 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+// VersionExtractor must get a schema version and the payload from an encoded item.
+type VersionExtractor[E any] func(encoded E) (version uint64, payload E, e error)
+
+// VersionLeadingByte uses the first byte as a schema version.
+func VersionLeadingByte(encoded []byte) (version uint64, payload []byte, e error) {
+	if len(encoded) < 1 {
+		return 0, nil, ErrInvalidSize
+	}
+	return uint64(encoded[0]), encoded[1:], nil
+}
+
+// VersionUvarint uses a leading uvarint as a schema version.
+func VersionUvarint(encoded []byte) (version uint64, payload []byte, e error) {
+	version, n := binary.Uvarint(encoded)
+	if n <= 0 {
+		return 0, nil, ErrInvalidSize
+	}
+	return version, encoded[n:], nil
+}
+
+// UnknownVersionError is returned when no Decode is registered for a version.
+type UnknownVersionError struct{ Version uint64 }
+
+func (u UnknownVersionError) Error() string {
+	return fmt.Sprintf("unknown version: %v", u.Version)
+}
+
+// MigrateNew creates a Decode which decodes an old layout and upgrades it.
+//
+// Migrations can be chained: MigrateNew(MigrateNew(v1, v1to2), v2to3).
+//
+// The Decode is safe for concurrent use if decode and migrate are.
+//
+// # Arguments
+//   - decode: Decodes an old layout.
+//   - migrate: Upgrades an old value to the current value.
+func MigrateNew[E, O, D any](
+	decode local.Decode[E, O],
+	migrate func(old O) (current D, e error),
+) local.Decode[E, D] {
+	return func(encoded E) (decoded D, e error) {
+		old, e := decode(encoded)
+		if nil != e {
+			return decoded, e
+		}
+		return migrate(old)
+	}
+}
+
+// Versioned dispatches an encoded item to a Decode registered for its version.
+type Versioned[E, D any] struct {
+	extract  VersionExtractor[E]
+	decoders map[uint64]local.Decode[E, D]
+}
+
+// VersionedNew creates a Versioned which has no registered Decode.
+//
+// # Arguments
+//   - extract: Gets a version and the payload.
+func VersionedNew[E, D any](extract VersionExtractor[E]) Versioned[E, D] {
+	return Versioned[E, D]{
+		extract:  extract,
+		decoders: map[uint64]local.Decode[E, D]{},
+	}
+}
+
+// WithVersion creates a new Versioned which uses the Decode for the version.
+//
+// # Arguments
+//   - version: The schema version.
+//   - decode: Decodes a payload(use MigrateNew for old versions).
+func (v Versioned[E, D]) WithVersion(version uint64, decode local.Decode[E, D]) Versioned[E, D] {
+	var decoders map[uint64]local.Decode[E, D] = make(
+		map[uint64]local.Decode[E, D],
+		len(v.decoders)+1,
+	)
+	for ver, dec := range v.decoders {
+		decoders[ver] = dec
+	}
+	decoders[version] = decode
+	return Versioned[E, D]{
+		extract:  v.extract,
+		decoders: decoders,
+	}
+}
+
+// Decode creates a Decode which dispatches an encoded item by its version.
+//
+// WithVersion copies the registered decoders so that the map is only read by the Decode;
+// it is safe for concurrent use if the extractor and the registered decoders are.
+func (v Versioned[E, D]) Decode() local.Decode[E, D] {
+	return func(encoded E) (decoded D, e error) {
+		version, payload, e := v.extract(encoded)
+		if nil != e {
+			return decoded, e
+		}
+		dec, found := v.decoders[version]
+		if !found {
+			return decoded, UnknownVersionError{Version: version}
+		}
+		return dec(payload)
+	}
+}
@@ -0,0 +1,117 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	local "github.com/takanoriyanagitani/go-local-filter/v2"
+)
+
+type testRowV1 struct {
+	Minute int32 `json:"m"`
+}
+
+type testRowV2 struct {
+	Minute int32 `json:"minute"`
+	Second uint8 `json:"second"`
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	v1to2 := func(old testRowV1) (testRowV2, error) {
+		return testRowV2{Minute: old.Minute}, nil
+	}
+	v2to3 := func(old testRowV2) (testRow, error) {
+		return testRow{Minute: old.Minute, Second: old.Second, Label: "migrated"}, nil
+	}
+
+	t.Run("leading byte", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = VersionedNew[[]byte, testRow](
+			VersionLeadingByte,
+		).
+			WithVersion(1, MigrateNew(MigrateNew(JsonNew[testRowV1](), v1to2), v2to3)).
+			WithVersion(2, MigrateNew(JsonNew[testRowV2](), v2to3)).
+			WithVersion(3, JsonNew[testRow]()).
+			Decode()
+
+		t.Run("v1", func(t *testing.T) {
+			t.Parallel()
+
+			decoded, e := decode(append([]byte{1}, `{"m":42}`...))
+			t.Run("no error", assertNil(e))
+			t.Run("minute", assertEq(decoded.Minute, 42))
+			t.Run("label", assertEq(decoded.Label, "migrated"))
+		})
+
+		t.Run("v2", func(t *testing.T) {
+			t.Parallel()
+
+			decoded, e := decode(append([]byte{2}, `{"minute":42,"second":3}`...))
+			t.Run("no error", assertNil(e))
+			t.Run("second", assertEq(decoded.Second, 3))
+			t.Run("label", assertEq(decoded.Label, "migrated"))
+		})
+
+		t.Run("v3", func(t *testing.T) {
+			t.Parallel()
+
+			decoded, e := decode(append([]byte{3}, `{"minute":42,"label":"hw"}`...))
+			t.Run("no error", assertNil(e))
+			t.Run("label", assertEq(decoded.Label, "hw"))
+		})
+
+		t.Run("unknown", func(t *testing.T) {
+			t.Parallel()
+
+			_, e := decode(append([]byte{4}, `{}`...))
+			var u UnknownVersionError
+			t.Run("typed error", assertEq(errors.As(e, &u), true))
+			t.Run("version", assertEq(u.Version, 4))
+		})
+
+		t.Run("empty", func(t *testing.T) {
+			t.Parallel()
+
+			_, e := decode(nil)
+			t.Run("error", assertEq(errors.Is(e, ErrInvalidSize), true))
+		})
+	})
+
+	t.Run("uvarint", func(t *testing.T) {
+		t.Parallel()
+
+		var decode local.Decode[[]byte, testRow] = VersionedNew[[]byte, testRow](VersionUvarint).
+			WithVersion(300, JsonNew[testRow]()).
+			Decode()
+
+		var encoded []byte = binary.AppendUvarint(nil, 300)
+		decoded, e := decode(append(encoded, `{"minute":599}`...))
+		t.Run("no error", assertNil(e))
+		t.Run("minute", assertEq(decoded.Minute, 599))
+	})
+
+	t.Run("extractor", func(t *testing.T) {
+		t.Parallel()
+
+		type tagged struct {
+			version uint64
+			payload string
+		}
+
+		var decode local.Decode[tagged, int] = VersionedNew[tagged, int](
+			func(encoded tagged) (uint64, tagged, error) {
+				return encoded.version, encoded, nil
+			},
+		).
+			WithVersion(7, func(encoded tagged) (int, error) { return len(encoded.payload), nil }).
+			Decode()
+
+		decoded, e := decode(tagged{version: 7, payload: "abc"})
+		t.Run("no error", assertNil(e))
+		t.Run("decoded", assertEq(decoded, 3))
+	})
+}
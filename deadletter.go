@@ -0,0 +1,283 @@
+package local
+
+import (
+	"context"
+)
+
+// DeadLetter must consume a raw item which could not be decoded(or unpacked).
+//
+// The raw item may be reused by the caller after DeadLetter returns;
+// it must be copied if it is retained.
+//
+// # Arguments
+//   - raw: The raw(encoded or packed) item.
+//   - ie: The error and the location of the item.
+//
+// # Return value
+//   - e: Must not be nil to abort the scan.
+type DeadLetter[T any] func(raw *T, ie ItemError) (e error)
+
+// DeadLetterKey must return the key of a raw item(nil if unknown).
+type DeadLetterKey[T any] func(raw *T) (key any)
+
+func (k DeadLetterKey[T]) get(raw *T) any {
+	if nil == k {
+		return nil
+	}
+	return k(raw)
+}
+
+// NewAllWithDeadLetter creates a closure which gets decoded items.
+//
+// Undecodable items are routed to the dead-letter consumer and skipped.
+//
+// The closure is safe for concurrent use if all, d, deadLetter and key are;
+// deadLetter may then be called by several goroutines at once.
+//
+// # Arguments
+//   - all: Gets encoded items.
+//   - deadLetter: Consumes undecodable items.
+//   - key: Gets the key of an encoded item(may be nil).
+func (d Decode[E, D]) NewAllWithDeadLetter(
+	all func(context.Context, Bucket) (encoded []E, e error),
+	deadLetter DeadLetter[E],
+	key DeadLetterKey[E],
+) func(context.Context, Bucket) (decoded []D, e error) {
+	return func(ctx context.Context, bkt Bucket) (decoded []D, e error) {
+		encoded, e := all(ctx, bkt)
+		if nil != e {
+			return nil, e
+		}
+		for ix := range encoded {
+			var encodedItem *E = &encoded[ix]
+			decodedItem, e := d(*encodedItem)
+			if nil != e {
+				var ie ItemError = ItemErrorNew(bkt, ix, key.get(encodedItem), e)
+				e = deadLetter(encodedItem, ie)
+				if nil != e {
+					return nil, e
+				}
+				continue
+			}
+			decoded = append(decoded, decodedItem)
+		}
+		return
+	}
+}
+
+// NewAllWithDeadLetter creates a closure which gets unpacked items.
+//
+// Unpackable items are routed to the dead-letter consumer and skipped.
+//
+// The closure is safe for concurrent use if all, u, deadLetter and key are;
+// deadLetter may then be called by several goroutines at once.
+//
+// # Arguments
+//   - all: Gets packed items.
+//   - deadLetter: Consumes unpackable items.
+//   - key: Gets the key of a packed item(may be nil).
+func (u Unpack[P, U]) NewAllWithDeadLetter(
+	all func(context.Context, Bucket) (packed []P, e error),
+	deadLetter DeadLetter[P],
+	key DeadLetterKey[P],
+) func(context.Context, Bucket) (unpacked []U, e error) {
+	return func(ctx context.Context, bkt Bucket) (unpacked []U, e error) {
+		packed, e := all(ctx, bkt)
+		if nil != e {
+			return nil, e
+		}
+		for ix := range packed {
+			var packedItem *P = &packed[ix]
+			chunk, e := u(*packedItem)
+			if nil != e {
+				var ie ItemError = ItemErrorNew(bkt, ix, key.get(packedItem), e)
+				e = deadLetter(packedItem, ie)
+				if nil != e {
+					return nil, e
+				}
+				continue
+			}
+			unpacked = append(unpacked, chunk...)
+		}
+		return
+	}
+}
+
+// RemoteFilterNewDecodedWithDeadLetter gets decoded items from encoded items
+// and routes undecodable items to a dead-letter consumer.
+//
+// The index of an ItemError is the position of the item in the encoded items.
+// The closure is safe for concurrent use if decode, remote, deadLetter and key are.
+//
+// # Arguments
+//   - decode: Gets a decoded item from an encoded item.
+//   - remote: Gets encoded items.
+//   - deadLetter: Consumes undecodable items.
+//   - key: Gets the key of an encoded item(may be nil).
+func RemoteFilterNewDecodedWithDeadLetter[E, D, F any](
+	decode Decode[E, D],
+	remote func(ctx context.Context, b Bucket, filter F) (encoded []E, e error),
+	deadLetter DeadLetter[E],
+	key DeadLetterKey[E],
+) func(ctx context.Context, b Bucket, filter F) (decoded []D, e error) {
+	return func(ctx context.Context, b Bucket, filter F) (decoded []D, e error) {
+		return decode.NewAllWithDeadLetter(
+			func(ctx context.Context, bkt Bucket) (encoded []E, e error) {
+				return remote(ctx, bkt, filter)
+			},
+			deadLetter,
+			key,
+		)(ctx, b)
+	}
+}
+
+// WithDeadLetter creates a closure which creates an Unnest for a scan(bucket)
+// which routes failed items to a dead-letter consumer.
+//
+// The Unnest returns no items(and no error) for a failed packed item.
+// An Unnest does not see packed items rejected by a filter so that it does not know
+// the position of a packed item; the index of an ItemError is IndexUnknown.
+// Use GetByKeysNewUnnestedWithDeadLetter to get the position and the key of a packed item.
+// The returned closure and an Unnest keep no state;
+// they are safe for concurrent use if n, deadLetter and key are.
+//
+// # Arguments
+//   - deadLetter: Consumes items which could not be unnested.
+//   - key: Gets the key of a packed item(may be nil).
+func (n Unnest[P, U]) WithDeadLetter(
+	deadLetter DeadLetter[P],
+	key DeadLetterKey[P],
+) func(bucket Bucket) Unnest[P, U] {
+	return func(bucket Bucket) Unnest[P, U] {
+		return func(packed *P) (unnested []U, e error) {
+			unnested, e = n(packed)
+			if nil != e {
+				var ie ItemError = ItemErrorNew(bucket, IndexUnknown, key.get(packed), e)
+				return nil, deadLetter(packed, ie)
+			}
+			return
+		}
+	}
+}
+
+// ConsumerDecodedNewWithDeadLetter creates a closure which creates an IterConsumerFiltered
+// for a scan which routes undecodable items to a dead-letter consumer.
+//
+// A new consumer must be created for each scan(bucket);
+// the index of an ItemError is the number of encoded items seen by the consumer.
+// The returned closure is safe for concurrent use; a consumer is not(it counts items).
+//
+// # Arguments
+//   - decodedConsumer: Uses decoded items.
+//   - decoder: Gets a decoded item from an encoded item.
+//   - filterEncoded: Checks if an encoded item must be used or not.
+//   - deadLetter: Consumes undecodable items.
+//   - key: Gets the key of an encoded item(may be nil).
+func ConsumerDecodedNewWithDeadLetter[E, D, F any](
+	decodedConsumer IterConsumerFiltered[D, F],
+	decoder func(encoded *E) (decoded D, e error),
+	filterEncoded func(encoded *E, filter *F) (keep bool),
+	deadLetter DeadLetter[E],
+	key DeadLetterKey[E],
+) func(bucket Bucket) IterConsumerFiltered[E, F] {
+	return func(bucket Bucket) IterConsumerFiltered[E, F] {
+		var index int = 0
+		return func(encoded *E, filter *F) (stop bool, e error) {
+			var ix int = index
+			index += 1
+			var keep bool = filterEncoded(encoded, filter)
+			if !keep {
+				return false, nil
+			}
+			decoded, e := decoder(encoded)
+			if nil != e {
+				e = deadLetter(encoded, ItemErrorNew(bucket, ix, key.get(encoded), e))
+				return nil != e, e
+			}
+			return decodedConsumer(&decoded, filter)
+		}
+	}
+}
+
+// GetByKeyNewDecodedWithDeadLetter creates a closure like GetByKeyNewDecoded
+// which routes an undecodable item to a dead-letter consumer.
+//
+// An undecodable item is reported as not found(got is false).
+// The key of an ItemError is the key used to get the item and the index is IndexUnknown.
+// An encoded item is saved to a buffer created for each call
+// so that a closure is safe for concurrent use if getEncodedByKey, decoder and deadLetter are.
+//
+// # Arguments
+//   - getEncodedByKey: Gets an encoded item.
+//   - decoder: Gets a decoded item from an encoded item.
+//   - deadLetter: Consumes undecodable items.
+func GetByKeyNewDecodedWithDeadLetter[G, K, E, D any](
+	getEncodedByKey func(ctx context.Context, con G, key K, encoded *E) (got bool, e error),
+	decoder Decode[*E, D],
+	deadLetter DeadLetter[E],
+) func(bucket Bucket) func(ctx context.Context, con G, key K, decoded *D) (got bool, e error) {
+	return func(bucket Bucket) func(context.Context, G, K, *D) (bool, error) {
+		return func(ctx context.Context, con G, key K, decoded *D) (got bool, e error) {
+			var buf E
+			got, e = getEncodedByKey(ctx, con, key, &buf)
+			if nil != e || !got {
+				return false, e
+			}
+			dec, e := decoder(&buf)
+			if nil != e {
+				return false, deadLetter(&buf, ItemErrorNew(bucket, IndexUnknown, key, e))
+			}
+			*decoded = dec
+			return true, nil
+		}
+	}
+}
+
+// GetByKeysNewUnnestedWithDeadLetter creates a closure like GetByKeysNewUnnested
+// which routes packed items which could not be unnested to a dead-letter consumer.
+//
+// The index of an ItemError is the position of the key in keys and the key is the key.
+// Each call tracks its own position so that the closures are safe for concurrent use
+// if their arguments are(buf must not be shared).
+//
+// # Arguments
+//   - getByKey: Gets a packed item by a key.
+//   - unnest: Gets unnested items from a packed item.
+//   - filterPacked: Checks if a packed item must be used or not.
+//   - filterUnpacked: Check if an unpacked item must be used or not.
+//   - consumeUnpacked: Uses an unpacked item.
+//   - deadLetter: Consumes packed items which could not be unnested.
+func GetByKeysNewUnnestedWithDeadLetter[G, K, P, F, U any](
+	getByKey func(ctx context.Context, con G, key K, packed *P) (got bool, e error),
+	unnest Unnest[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	filterUnpacked func(unpacked *U, filter *F) (keep bool),
+	consumeUnpacked IterConsumer[U],
+	deadLetter DeadLetter[P],
+) func(bucket Bucket) func(ctx context.Context, keys []K, get G, buf *P, filter *F) error {
+	return func(bucket Bucket) func(context.Context, []K, G, *P, *F) error {
+		return func(ctx context.Context, keys []K, con G, buf *P, filter *F) error {
+			var ix int = -1
+			var current K
+			track := func(ctx context.Context, con G, key K, packed *P) (got bool, e error) {
+				ix += 1
+				current = key
+				return getByKey(ctx, con, key, packed)
+			}
+			routed := func(packed *P) (unnested []U, e error) {
+				unnested, e = unnest(packed)
+				if nil != e {
+					return nil, deadLetter(packed, ItemErrorNew(bucket, ix, current, e))
+				}
+				return
+			}
+			return GetByKeysNewUnnested(
+				track,
+				routed,
+				filterPacked,
+				filterUnpacked,
+				consumeUnpacked,
+			)(ctx, keys, con, buf, filter)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Decode", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("NewAllWithDeadLetter", func(t *testing.T) {
+			t.Parallel()
+
+			var decode Decode[testEncodedRow, testDecodedRow] = func(e testEncodedRow) (
+				dec testDecodedRow,
+				err error,
+			) {
+				return e.Decode()
+			}
+
+			getEncoded := func(_ context.Context, _b Bucket) ([]testEncodedRow, error) {
+				return []testEncodedRow{
+					{key: []byte{0x42}, val: []byte{0, 0, 0, 0, 0, 0, 0, 1}},
+					{key: []byte{0x43}, val: []byte{0, 0, 0, 0, 0, 0, 2}},
+				}, nil
+			}
+
+			var dead []testEncodedRow
+			var errs []ItemError
+			var deadLetter DeadLetter[testEncodedRow] = func(
+				raw *testEncodedRow,
+				ie ItemError,
+			) error {
+				dead = append(dead, *raw)
+				errs = append(errs, ie)
+				return nil
+			}
+
+			getDecoded := decode.NewAllWithDeadLetter(
+				getEncoded,
+				deadLetter,
+				func(raw *testEncodedRow) any { return raw.key[0] },
+			)
+			decoded, e := getDecoded(context.Background(), BucketNew("b"))
+			t.Run("no error", assertNil(e))
+			t.Run("single item", assertEq(len(decoded), 1))
+			t.Run("single dead item", assertEq(len(dead), 1))
+			t.Run("raw val kept", assertEq(len(dead[0].val), 7))
+			t.Run("index", assertEq(errs[0].Index(), 1))
+			t.Run("key", assertEq(errs[0].Key().(uint8), 0x43))
+			t.Run("bucket", assertEq(errs[0].Bucket().AsString(), "b"))
+			t.Run("cause", assertEq(errors.Is(errs[0], testErrorInvalidVal), true))
+		})
+
+		var decode Decode[testEncodedRow, testDecodedRow] = func(e testEncodedRow) (
+			testDecodedRow,
+			error,
+		) {
+			return e.Decode()
+		}
+		var rows []testEncodedRow = []testEncodedRow{
+			{key: []byte{0x41}, val: []byte{0, 0, 0, 0, 0, 0, 0, 1}},
+			{key: []byte{0x42}, val: []byte{0, 0, 0, 0, 0, 0, 0, 2}},
+			{key: []byte{0x43}, val: []byte{0, 0, 0, 0, 0, 0, 3}},
+		}
+		keyOf := func(raw *testEncodedRow) any { return raw.key[0] }
+
+		t.Run("RemoteFilterNewDecodedWithDeadLetter", func(t *testing.T) {
+			t.Parallel()
+
+			var errs []ItemError
+			getDecoded := RemoteFilterNewDecodedWithDeadLetter(
+				decode,
+				func(_ context.Context, _ Bucket, skip int) ([]testEncodedRow, error) {
+					return rows[skip:], nil
+				},
+				func(raw *testEncodedRow, ie ItemError) error {
+					errs = append(errs, ie)
+					return nil
+				},
+				keyOf,
+			)
+			decoded, e := getDecoded(context.Background(), BucketNew("b"), 1)
+			t.Run("no error", assertNil(e))
+			t.Run("single item", assertEq(len(decoded), 1))
+			t.Run("single dead item", assertEq(len(errs), 1))
+			t.Run("index", assertEq(errs[0].Index(), 1))
+			t.Run("key", assertEq(errs[0].Key().(uint8), 0x43))
+		})
+
+		t.Run("ConsumerDecodedNewWithDeadLetter", func(t *testing.T) {
+			t.Parallel()
+
+			var errs []ItemError
+			var decoded []testDecodedRow
+			consumerNew := ConsumerDecodedNewWithDeadLetter(
+				func(d *testDecodedRow, _ *uint8) (stop bool, e error) {
+					decoded = append(decoded, *d)
+					return false, nil
+				},
+				DecodeNewPtr(decode),
+				func(encoded *testEncodedRow, skip *uint8) (keep bool) {
+					return *skip != encoded.key[0]
+				},
+				func(raw *testEncodedRow, ie ItemError) error {
+					errs = append(errs, ie)
+					return nil
+				},
+				keyOf,
+			)
+
+			var skip uint8 = 0x41
+			for _, bucket := range []string{"b", "c"} {
+				var consumer IterConsumerFiltered[testEncodedRow, uint8] = consumerNew(BucketNew(bucket))
+				for ix := range rows {
+					stop, e := consumer(&rows[ix], &skip)
+					t.Run("no error", assertNil(e))
+					t.Run("not stopped", assertEq(stop, false))
+				}
+			}
+			t.Run("2 items", assertEq(len(decoded), 2))
+			t.Run("2 dead items", assertEq(len(errs), 2))
+			t.Run("index", assertEq(errs[0].Index(), 2))
+			t.Run("key", assertEq(errs[0].Key().(uint8), 0x43))
+			t.Run("bucket", assertEq(errs[0].Bucket().AsString(), "b"))
+			t.Run("index of a scan", assertEq(errs[1].Index(), 2))
+			t.Run("bucket of a scan", assertEq(errs[1].Bucket().AsString(), "c"))
+
+			var abort IterConsumerFiltered[testEncodedRow, uint8] = ConsumerDecodedNewWithDeadLetter(
+				func(_ *testDecodedRow, _ *uint8) (stop bool, e error) { return false, nil },
+				DecodeNewPtr(decode),
+				func(_ *testEncodedRow, _ *uint8) (keep bool) { return true },
+				func(_ *testEncodedRow, ie ItemError) error { return ie },
+				nil,
+			)(BucketNew("b"))
+			stop, e := abort(&rows[2], &skip)
+			t.Run("stopped", assertEq(stop, true))
+			t.Run("aborted", assertEq(errors.Is(e, testErrorInvalidVal), true))
+		})
+
+		t.Run("GetByKeyNewDecodedWithDeadLetter", func(t *testing.T) {
+			t.Parallel()
+
+			var dead []testEncodedRow
+			var errs []ItemError
+			getByKey := GetByKeyNewDecodedWithDeadLetter(
+				func(_ context.Context, _ struct{}, key uint8, encoded *testEncodedRow) (bool, error) {
+					for _, row := range rows {
+						if key == row.key[0] {
+							*encoded = row
+							return true, nil
+						}
+					}
+					return false, nil
+				},
+				DecodeNewPtr(decode),
+				func(raw *testEncodedRow, ie ItemError) error {
+					dead = append(dead, *raw)
+					errs = append(errs, ie)
+					return nil
+				},
+			)(BucketNew("b"))
+
+			var decoded testDecodedRow
+			got, e := getByKey(context.Background(), struct{}{}, 0x42, &decoded)
+			t.Run("no error", assertNil(e))
+			t.Run("got", assertEq(got, true))
+			t.Run("val", assertEq(decoded.val, 2))
+
+			got, e = getByKey(context.Background(), struct{}{}, 0x43, &decoded)
+			t.Run("no error", assertNil(e))
+			t.Run("not got", assertEq(got, false))
+			t.Run("single dead item", assertEq(len(dead), 1))
+			t.Run("dead val", assertEq(len(dead[0].val), 7))
+			t.Run("key", assertEq(errs[0].Key().(uint8), 0x43))
+			t.Run("index unknown", assertEq(errs[0].Index(), IndexUnknown))
+
+			got, e = getByKey(context.Background(), struct{}{}, 0x44, &decoded)
+			t.Run("no error", assertNil(e))
+			t.Run("missing", assertEq(got, false))
+			t.Run("no more dead items", assertEq(len(dead), 1))
+		})
+	})
+
+	t.Run("Unpack", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("NewAllWithDeadLetter", func(t *testing.T) {
+			t.Parallel()
+
+			getPacked := func(_ context.Context, _b Bucket) ([]testPackedRow, error) {
+				return []testPackedRow{
+					{key: 0x00, val: 0x0123456789abcdef},
+					{key: 0x01, val: 0x0123456789abcdef},
+				}, nil
+			}
+			var unpack Unpack[testPackedRow, testUnpackedRow] = func(
+				packed testPackedRow,
+			) (unpacked []testUnpackedRow, e error) {
+				if 0 == packed.key {
+					return nil, testErrorInvalidKey
+				}
+				return packed.unpack(), nil
+			}
+
+			t.Run("skip", func(t *testing.T) {
+				t.Parallel()
+
+				var dead []testPackedRow
+				getUnpacked := unpack.NewAllWithDeadLetter(
+					getPacked,
+					func(raw *testPackedRow, ie ItemError) error {
+						dead = append(dead, *raw)
+						return nil
+					},
+					nil,
+				)
+				unpacked, e := getUnpacked(context.Background(), BucketNew(""))
+				t.Run("no error", assertNil(e))
+				t.Run("4 items", assertEq(len(unpacked), 4))
+				t.Run("single dead item", assertEq(len(dead), 1))
+				t.Run("dead val", assertEq(dead[0].val, 0x0123456789abcdef))
+			})
+
+			t.Run("abort", func(t *testing.T) {
+				t.Parallel()
+
+				getUnpacked := unpack.NewAllWithDeadLetter(
+					getPacked,
+					func(raw *testPackedRow, ie ItemError) error { return ie },
+					nil,
+				)
+				_, e := getUnpacked(context.Background(), BucketNew(""))
+				t.Run("error", assertEq(errors.Is(e, testErrorInvalidKey), true))
+			})
+		})
+	})
+
+	t.Run("Unnest", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("WithDeadLetter", func(t *testing.T) {
+			t.Parallel()
+
+			var unnest Unnest[testNestPackedItem, testNestUnpackedItem] = func(
+				packed *testNestPackedItem,
+			) (unnested []testNestUnpackedItem, e error) {
+				if 0 == packed.key {
+					return nil, testErrorInvalidKey
+				}
+				return packed.Unpack(), nil
+			}
+
+			var dead []testNestPackedItem
+			var errs []ItemError
+			var withDeadNew func(Bucket) Unnest[testNestPackedItem, testNestUnpackedItem] = unnest.
+				WithDeadLetter(
+					func(raw *testNestPackedItem, ie ItemError) error {
+						dead = append(dead, *raw)
+						errs = append(errs, ie)
+						return nil
+					},
+					func(raw *testNestPackedItem) any { return raw.key },
+				)
+			var withDead Unnest[testNestPackedItem, testNestUnpackedItem] = withDeadNew(BucketNew("b"))
+
+			var buf []testNestUnpackedItem
+			var consumer IterConsumer[testNestUnpackedItem] = func(
+				value *testNestUnpackedItem,
+			) (stop bool, e error) {
+				buf = append(buf, *value)
+				return false, nil
+			}
+
+			f := Iter2ConsumerNewUnnested(
+				func(iter *uint8) (hasNext bool) { return *iter < 3 },
+				func(iter *uint8, p *testNestPackedItem) error {
+					p.key = *iter
+					*iter += 1
+					return nil
+				},
+				func(iter *uint8) error { return nil },
+				withDead,
+				func(packed *testNestPackedItem, f *testNestFilter) (keep bool) { return true },
+				func(unpacked *testNestUnpackedItem, f *testNestFilter) (keep bool) { return true },
+				consumer,
+			)
+
+			var iter uint8 = 0
+			var packedBuf testNestPackedItem
+			var filter testNestFilter
+			e := f(context.Background(), &iter, &packedBuf, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("16 unpacked items", assertEq(len(buf), 16))
+			t.Run("single dead item", assertEq(len(dead), 1))
+			t.Run("index unknown", assertEq(errs[0].Index(), IndexUnknown))
+			t.Run("key", assertEq(errs[0].Key().(uint8), 0))
+			t.Run("message", assertEq(errs[0].Error(), "bucket=b key=0: invalid key"))
+
+			iter = 0
+			e = f(context.Background(), &iter, &packedBuf, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("second scan", assertEq(len(errs), 2))
+			t.Run("same item", assertEq(errs[1].Error(), errs[0].Error()))
+
+			var other testNestPackedItem
+			_, e = withDeadNew(BucketNew("c"))(&other)
+			t.Run("no error", assertNil(e))
+			t.Run("bucket of a scan", assertEq(errs[2].Bucket().AsString(), "c"))
+		})
+
+		t.Run("GetByKeysNewUnnestedWithDeadLetter", func(t *testing.T) {
+			t.Parallel()
+
+			var unnest Unnest[testNestPackedItem, testNestUnpackedItem] = func(
+				packed *testNestPackedItem,
+			) (unnested []testNestUnpackedItem, e error) {
+				if 0 == packed.key {
+					return nil, testErrorInvalidKey
+				}
+				return packed.Unpack(), nil
+			}
+
+			var errs []ItemError
+			var unpacked int = 0
+			f := GetByKeysNewUnnestedWithDeadLetter(
+				func(_ context.Context, _ struct{}, key uint8, p *testNestPackedItem) (bool, error) {
+					p.key = key
+					return 3 != key, nil
+				},
+				unnest,
+				func(packed *testNestPackedItem, f *testNestFilter) (keep bool) { return true },
+				func(unpacked *testNestUnpackedItem, f *testNestFilter) (keep bool) { return true },
+				func(_ *testNestUnpackedItem) (stop bool, e error) {
+					unpacked += 1
+					return false, nil
+				},
+				func(raw *testNestPackedItem, ie ItemError) error {
+					errs = append(errs, ie)
+					return nil
+				},
+			)(BucketNew("b"))
+
+			var buf testNestPackedItem
+			var filter testNestFilter
+			e := f(context.Background(), []uint8{3, 1, 0, 2}, struct{}{}, &buf, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("16 unpacked items", assertEq(unpacked, 16))
+			t.Run("single dead item", assertEq(len(errs), 1))
+			t.Run("index of the key", assertEq(errs[0].Index(), 2))
+			t.Run("key", assertEq(errs[0].Key().(uint8), 0))
+			t.Run("bucket", assertEq(errs[0].Bucket().AsString(), "b"))
+
+			e = f(context.Background(), []uint8{0}, struct{}{}, &buf, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("index of a call", assertEq(errs[1].Index(), 0))
+		})
+	})
+}
@@ -9,6 +9,9 @@ type Decode[E, D any] func(encoded E) (decoded D, e error)
 
 // NewAll creates a closure which gets decoded items.
 //
+// Decoded items are collected for each call;
+// the closure is safe for concurrent use if all and d are.
+//
 // # Arguments
 //   - all: Gets encoded items.
 func (d Decode[E, D]) NewAll(
@@ -33,6 +36,8 @@ func (d Decode[E, D]) NewAll(
 
 // RemoteFilterNewDecoded gets decoded items from encoded items.
 //
+// The closure keeps no state; it is safe for concurrent use if decode and remote are.
+//
 // # Arguments
 //   - decode: Gets a decoded item from an encoded item.
 //   - remote: Gets encoded items.
@@ -56,3 +61,81 @@ func RemoteFilterNewDecoded[E, D, F any](
 		)(b)
 	}
 }
+
+func (d Decode[E, D]) decodeManyWithPolicy(
+	bkt Bucket,
+	encoded []E,
+	policy ErrorPolicy,
+	report *ErrorReport,
+) (decoded []D, e error) {
+	for ix, encodedItem := range encoded {
+		decodedItem, e := d(encodedItem)
+		if nil != e {
+			e = report.Handle(policy, ItemErrorNew(bkt, ix, nil, e))
+			if nil != e {
+				return nil, e
+			}
+			continue
+		}
+		decoded = append(decoded, decodedItem)
+	}
+	return
+}
+
+// NewAllWithPolicy creates a closure which gets decoded items using an ErrorPolicy.
+//
+// Each call fills its own ErrorReport; the closure is safe for concurrent use if all and d are.
+//
+// # Arguments
+//   - all: Gets encoded items.
+//   - policy: Decides what to do with an item which can not be decoded.
+func (d Decode[E, D]) NewAllWithPolicy(
+	all func(context.Context, Bucket) (encoded []E, e error),
+	policy ErrorPolicy,
+) func(context.Context, Bucket) (decoded []D, report ErrorReport, e error) {
+	return func(ctx context.Context, bkt Bucket) (decoded []D, report ErrorReport, e error) {
+		encoded, e := all(ctx, bkt)
+		if nil != e {
+			return nil, report, e
+		}
+		decoded, e = d.decodeManyWithPolicy(bkt, encoded, policy, &report)
+		return
+	}
+}
+
+// RemoteFilterNewDecodedWithPolicy gets decoded items using an ErrorPolicy.
+//
+// Each call fills its own ErrorReport;
+// the closure is safe for concurrent use if decode and remote are.
+//
+// # Arguments
+//   - decode: Gets a decoded item from an encoded item.
+//   - remote: Gets encoded items.
+//   - policy: Decides what to do with an item which can not be decoded.
+func RemoteFilterNewDecodedWithPolicy[E, D, F any](
+	decode Decode[E, D],
+	remote func(ctx context.Context, b Bucket, filter F) (encoded []E, e error),
+	policy ErrorPolicy,
+) func(ctx context.Context, b Bucket, filter F) (decoded []D, report ErrorReport, e error) {
+	return func(ctx context.Context, b Bucket, filter F) (
+		decoded []D,
+		report ErrorReport,
+		e error,
+	) {
+		encoded, e := remote(ctx, b, filter)
+		if nil != e {
+			return nil, report, e
+		}
+		decoded, e = decode.decodeManyWithPolicy(b, encoded, policy, &report)
+		return
+	}
+}
+
+// DecodeNewPtr creates a new Decode which gets a decoded item from a pointer to an encoded item.
+//
+// The returned Decode can be used by GetByKeyNewDecoded or ConsumerDecodedNew.
+//
+// The returned Decode is safe for concurrent use if d is.
+func DecodeNewPtr[E, D any](d Decode[E, D]) Decode[*E, D] {
+	return func(encoded *E) (decoded D, e error) { return d(*encoded) }
+}
@@ -221,3 +221,87 @@ func TestDecode(t *testing.T) {
 		})
 	})
 }
+
+func TestDecodeWithPolicy(t *testing.T) {
+	t.Parallel()
+
+	var decode Decode[testEncodedRow, testDecodedRow] = func(e testEncodedRow) (
+		dec testDecodedRow,
+		err error,
+	) {
+		return e.Decode()
+	}
+
+	getEncoded := func(_ context.Context, _b Bucket) ([]testEncodedRow, error) {
+		return []testEncodedRow{
+			{key: []byte{0x42}, val: []byte{0, 0, 0, 0, 0, 0, 0, 1}},
+			{key: []byte{0x42}, val: []byte{0, 0, 0, 0, 0, 0, 2}},
+			{key: []byte{}, val: []byte{0, 0, 0, 0, 0, 0, 0, 3}},
+			{key: []byte{0x43}, val: []byte{0, 0, 0, 0, 0, 0, 0, 4}},
+		}, nil
+	}
+
+	t.Run("Decode", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("NewAllWithPolicy", func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("fail fast", func(t *testing.T) {
+				t.Parallel()
+
+				getDecoded := decode.NewAllWithPolicy(getEncoded, ErrorPolicyFailFast)
+				_, _, e := getDecoded(context.Background(), BucketNew("b"))
+				t.Run("error", assertEq(errors.Is(e, testErrorInvalidVal), true))
+
+				var ie ItemError
+				t.Run("item error", assertEq(errors.As(e, &ie), true))
+				t.Run("index", assertEq(ie.Index(), 1))
+			})
+
+			t.Run("skip count", func(t *testing.T) {
+				t.Parallel()
+
+				getDecoded := decode.NewAllWithPolicy(getEncoded, ErrorPolicySkipCount)
+				decoded, report, e := getDecoded(context.Background(), BucketNew("b"))
+				t.Run("no error", assertNil(e))
+				t.Run("2 items", assertEq(len(decoded), 2))
+				t.Run("2 skipped", assertEq(report.Skipped(), 2))
+				t.Run("no errors", assertEq(len(report.Errors()), 0))
+			})
+
+			t.Run("skip collect", func(t *testing.T) {
+				t.Parallel()
+
+				getDecoded := decode.NewAllWithPolicy(getEncoded, ErrorPolicySkipCollect)
+				decoded, report, e := getDecoded(context.Background(), BucketNew("b"))
+				t.Run("no error", assertNil(e))
+				t.Run("2 items", assertEq(len(decoded), 2))
+				t.Run("2 errors", assertEq(len(report.Errors()), 2))
+
+				var errs []ItemError = report.Errors()
+				t.Run("index 1", assertEq(errs[0].Index(), 1))
+				t.Run("index 2", assertEq(errs[1].Index(), 2))
+				t.Run("invalid key", assertEq(errors.Is(errs[1], testErrorInvalidKey), true))
+				t.Run("bucket", assertEq(errs[1].Bucket().AsString(), "b"))
+			})
+		})
+	})
+
+	t.Run("RemoteFilterNewDecodedWithPolicy", func(t *testing.T) {
+		t.Parallel()
+
+		getDecoded := RemoteFilterNewDecodedWithPolicy(
+			decode,
+			func(ctx context.Context, b Bucket, _filter uint8) ([]testEncodedRow, error) {
+				return getEncoded(ctx, b)
+			},
+			ErrorPolicySkipCollect,
+		)
+		decoded, report, e := getDecoded(context.Background(), BucketNew("b"), 0x42)
+		t.Run("no error", assertNil(e))
+		t.Run("2 items", assertEq(len(decoded), 2))
+		t.Run("2 errors", assertEq(len(report.Errors()), 2))
+		t.Run("last item", assertEq(decoded[1].val, 4))
+	})
+}
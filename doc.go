@@ -0,0 +1,13 @@
+// Package local filters partially filtered rows got from a data store.
+//
+// # Concurrency
+//
+// Each constructor(a function or a method which returns a closure) documents
+// whether the returned closure can be used by several goroutines at once.
+//
+// A closure which is safe for concurrent use keeps no mutable state between calls.
+// It is safe only if the closures passed to its constructor are safe,
+// and a buffer passed to a call(e.g, buf) must not be shared with other calls.
+//
+// A closure which is not safe for concurrent use must be created for each goroutine.
+package local
@@ -0,0 +1,165 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRoundTrip is returned(wrapped by RoundTripError) when a round trip changes a value.
+var ErrRoundTrip = errors.New("round trip mismatch")
+
+// RoundTripError describes a sample which did not survive a round trip.
+//
+// The error of an encoder or a decoder is wrapped(nil for a mismatch).
+type RoundTripError struct {
+	index int
+	err   error
+}
+
+// Index returns the index of the sample.
+func (r RoundTripError) Index() int { return r.index }
+
+func (r RoundTripError) Error() string {
+	if nil != r.err {
+		return fmt.Sprintf("%v: index=%v: %v", ErrRoundTrip, r.index, r.err)
+	}
+	return fmt.Sprintf("%v: index=%v", ErrRoundTrip, r.index)
+}
+
+// Unwrap returns the error of an encoder or a decoder.
+func (r RoundTripError) Unwrap() error { return r.err }
+
+// Is returns true for ErrRoundTrip.
+func (r RoundTripError) Is(target error) bool { return ErrRoundTrip == target }
+
+// Encode must encode a decoded item.
+type Encode[D, E any] func(decoded D) (encoded E, e error)
+
+// EncodeAll encodes items.
+func (c Encode[D, E]) EncodeAll(decoded []D) (encoded []E, e error) {
+	for _, decodedItem := range decoded {
+		encodedItem, e := c(decodedItem)
+		if nil != e {
+			return nil, e
+		}
+		encoded = append(encoded, encodedItem)
+	}
+	return
+}
+
+// EncodeCompose creates an Encode which uses f and then g.
+//
+// The Encode is safe for concurrent use if f and g are.
+func EncodeCompose[A, B, C any](f Encode[A, B], g Encode[B, C]) Encode[A, C] {
+	return composeErr(f, g)
+}
+
+// DecodeCompose creates a Decode which uses f and then g(e.g, decompress and then decode).
+//
+// Like EncodeCompose, the Decode keeps no state of its own.
+func DecodeCompose[A, B, C any](f Decode[A, B], g Decode[B, C]) Decode[A, C] {
+	return composeErr(f, g)
+}
+
+// Pack must get a packed item from unpacked items.
+type Pack[U, P any] func(unpacked []U) (packed P, e error)
+
+// PackCompose creates a Pack which packs unpacked items by p and then encodes it by g.
+//
+// The Pack is safe for concurrent use if p and g are.
+func PackCompose[U, P, E any](p Pack[U, P], g Encode[P, E]) Pack[U, E] {
+	return composeErr(p, g)
+}
+
+// UnpackCompose creates an Unpack which decodes an encoded item by d and then unpacks it by u.
+//
+// It is the inverse of PackCompose and keeps no state of its own.
+func UnpackCompose[E, P, U any](d Decode[E, P], u Unpack[P, U]) Unpack[E, U] {
+	return composeErr(d, u)
+}
+
+// PackChunks creates packed items which contain at most size unpacked items.
+//
+// # Arguments
+//   - unpacked: Items to pack.
+//   - size: Max number of unpacked items in a packed item(at least 1).
+func (p Pack[U, P]) PackChunks(unpacked []U, size int) (packed []P, e error) {
+	if size < 1 {
+		size = 1
+	}
+	for lbi := 0; lbi < len(unpacked); lbi += size {
+		var ube int = lbi + size
+		if len(unpacked) < ube {
+			ube = len(unpacked)
+		}
+		packedItem, e := p(unpacked[lbi:ube])
+		if nil != e {
+			return nil, e
+		}
+		packed = append(packed, packedItem)
+	}
+	return
+}
+
+// RoundTripDecode checks Decode(Encode(x)) == x for all samples.
+//
+// # Arguments
+//   - encode: Encodes a sample.
+//   - decode: Decodes an encoded sample.
+//   - same: Checks if two decoded values are the same.
+//   - samples: Values to check.
+func RoundTripDecode[D, E any](
+	encode Encode[D, E],
+	decode Decode[E, D],
+	same func(a, b D) bool,
+	samples []D,
+) error {
+	for ix, sample := range samples {
+		encoded, e := encode(sample)
+		if nil != e {
+			return RoundTripError{index: ix, err: e}
+		}
+		decoded, e := decode(encoded)
+		if nil != e {
+			return RoundTripError{index: ix, err: e}
+		}
+		if !same(sample, decoded) {
+			return RoundTripError{index: ix}
+		}
+	}
+	return nil
+}
+
+// RoundTripUnpack checks Unpack(Pack(xs)) == xs for all samples.
+//
+// # Arguments
+//   - pack: Packs a sample.
+//   - unpack: Unpacks a packed sample.
+//   - same: Checks if two unpacked values are the same.
+//   - samples: Values to check.
+func RoundTripUnpack[U, P any](
+	pack Pack[U, P],
+	unpack Unpack[P, U],
+	same func(a, b U) bool,
+	samples [][]U,
+) error {
+	for ix, sample := range samples {
+		packed, e := pack(sample)
+		if nil != e {
+			return RoundTripError{index: ix, err: e}
+		}
+		unpacked, e := unpack(packed)
+		if nil != e {
+			return RoundTripError{index: ix, err: e}
+		}
+		if len(sample) != len(unpacked) {
+			return RoundTripError{index: ix}
+		}
+		for i := range sample {
+			if !same(sample[i], unpacked[i]) {
+				return RoundTripError{index: ix}
+			}
+		}
+	}
+	return nil
+}
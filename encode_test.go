@@ -0,0 +1,187 @@
+package local
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	var encode Encode[testDecodedRow, testEncodedRow] = func(d testDecodedRow) (
+		testEncodedRow,
+		error,
+	) {
+		var val []byte = make([]byte, 8)
+		binary.BigEndian.PutUint64(val, d.val)
+		return testEncodedRow{key: []byte{d.key}, val: val}, nil
+	}
+	var decode Decode[testEncodedRow, testDecodedRow] = func(e testEncodedRow) (
+		testDecodedRow,
+		error,
+	) {
+		return e.Decode()
+	}
+	same := func(a, b testDecodedRow) bool { return a == b }
+
+	t.Run("EncodeAll", func(t *testing.T) {
+		t.Parallel()
+
+		encoded, e := encode.EncodeAll([]testDecodedRow{{key: 1, val: 2}, {key: 3, val: 4}})
+		t.Run("no error", assertNil(e))
+		t.Run("2 items", assertEq(len(encoded), 2))
+		t.Run("key", assertEq(encoded[1].key[0], 3))
+	})
+
+	t.Run("EncodeCompose", func(t *testing.T) {
+		t.Parallel()
+
+		var double Encode[uint64, testDecodedRow] = func(u uint64) (testDecodedRow, error) {
+			return testDecodedRow{key: 0x42, val: u << 1}, nil
+		}
+		var composed Encode[uint64, testEncodedRow] = EncodeCompose(double, encode)
+		encoded, e := composed(0x21)
+		t.Run("no error", assertNil(e))
+		t.Run("val", assertEq(binary.BigEndian.Uint64(encoded.val), 0x42))
+	})
+
+	t.Run("DecodeCompose", func(t *testing.T) {
+		t.Parallel()
+
+		var half Decode[testDecodedRow, uint64] = func(d testDecodedRow) (uint64, error) {
+			return d.val >> 1, nil
+		}
+		var composed Decode[testEncodedRow, uint64] = DecodeCompose(decode, half)
+		decoded, e := composed(testEncodedRow{key: []byte{0}, val: []byte{0, 0, 0, 0, 0, 0, 0, 8}})
+		t.Run("no error", assertNil(e))
+		t.Run("val", assertEq(decoded, 4))
+	})
+
+	t.Run("RoundTripDecode", func(t *testing.T) {
+		t.Parallel()
+
+		var samples []testDecodedRow = []testDecodedRow{
+			{key: 0x00, val: 0},
+			{key: 0x42, val: 0x0123456789abcdef},
+			{key: 0xff, val: 0xffffffffffffffff},
+		}
+
+		t.Run("ok", assertNil(RoundTripDecode(encode, decode, same, samples)))
+
+		var lossy Encode[testDecodedRow, testEncodedRow] = func(d testDecodedRow) (
+			testEncodedRow,
+			error,
+		) {
+			d.val &= 0xff
+			return encode(d)
+		}
+		e := RoundTripDecode(lossy, decode, same, samples)
+		t.Run("mismatch", assertEq(errors.Is(e, ErrRoundTrip), true))
+
+		var r RoundTripError
+		t.Run("typed", assertEq(errors.As(e, &r), true))
+		t.Run("index", assertEq(r.Index(), 1))
+
+		var broken Decode[testEncodedRow, testDecodedRow] = func(e testEncodedRow) (
+			testDecodedRow,
+			error,
+		) {
+			return testDecodedRow{}, testErrorInvalidVal
+		}
+		e = RoundTripDecode(encode, broken, same, samples)
+		t.Run("decode error", assertEq(errors.Is(e, testErrorInvalidVal), true))
+		t.Run("round trip error", assertEq(errors.As(e, &r), true))
+		t.Run("first index", assertEq(r.Index(), 0))
+		t.Run("not an item error", assertEq(errors.As(e, new(ItemError)), false))
+	})
+
+	t.Run("Pack", func(t *testing.T) {
+		t.Parallel()
+
+		var pack Pack[testUnpackedRow, testPackedRow] = func(
+			unpacked []testUnpackedRow,
+		) (packed testPackedRow, e error) {
+			for ix, u := range unpacked {
+				packed.key = uint8(u.key >> 8)
+				var item uint64 = (uint64(u.key&0xff) << 8) | uint64(u.flag)
+				packed.val |= item << (0x30 - 0x10*ix)
+			}
+			return
+		}
+		var unpack Unpack[testPackedRow, testUnpackedRow] = func(
+			packed testPackedRow,
+		) (unpacked []testUnpackedRow, e error) {
+			return packed.unpack(), nil
+		}
+		sameUnpacked := func(a, b testUnpackedRow) bool { return a == b }
+
+		t.Run("PackChunks", func(t *testing.T) {
+			t.Parallel()
+
+			var unpacked []testUnpackedRow = make([]testUnpackedRow, 9)
+			packed, e := pack.PackChunks(unpacked, 4)
+			t.Run("no error", assertNil(e))
+			t.Run("3 packed items", assertEq(len(packed), 3))
+		})
+
+		t.Run("RoundTripUnpack", func(t *testing.T) {
+			t.Parallel()
+
+			var samples [][]testUnpackedRow = [][]testUnpackedRow{
+				{
+					{key: 0x4201, flag: 1},
+					{key: 0x4202, flag: 2},
+					{key: 0x4203, flag: 3},
+					{key: 0x4204, flag: 4},
+				},
+				{
+					{key: 0x4201, flag: 1},
+				},
+			}
+			e := RoundTripUnpack(pack, unpack, sameUnpacked, samples[:1])
+			t.Run("ok", assertNil(e))
+
+			e = RoundTripUnpack(pack, unpack, sameUnpacked, samples)
+			t.Run("length mismatch", assertEq(errors.Is(e, ErrRoundTrip), true))
+		})
+
+		t.Run("PackCompose", func(t *testing.T) {
+			t.Parallel()
+
+			var toBytes Encode[testPackedRow, []byte] = func(p testPackedRow) ([]byte, error) {
+				var encoded []byte = []byte{p.key}
+				return binary.BigEndian.AppendUint64(encoded, p.val), nil
+			}
+			var fromBytes Decode[[]byte, testPackedRow] = func(b []byte) (testPackedRow, error) {
+				if 9 != len(b) {
+					return testPackedRow{}, testErrorInvalidVal
+				}
+				return testPackedRow{key: b[0], val: binary.BigEndian.Uint64(b[1:])}, nil
+			}
+			var packBytes Pack[testUnpackedRow, []byte] = PackCompose(pack, toBytes)
+			var unpackBytes Unpack[[]byte, testUnpackedRow] = UnpackCompose(fromBytes, unpack)
+
+			encoded, e := packBytes([]testUnpackedRow{{key: 0x4201, flag: 1}})
+			t.Run("no error", assertNil(e))
+			t.Run("9 bytes", assertEq(len(encoded), 9))
+			t.Run("key", assertEq(encoded[0], 0x42))
+
+			var samples [][]testUnpackedRow = [][]testUnpackedRow{{
+				{key: 0x4201, flag: 1},
+				{key: 0x4202, flag: 2},
+				{key: 0x4203, flag: 3},
+				{key: 0x4204, flag: 4},
+			}}
+			e = RoundTripUnpack(packBytes, unpackBytes, sameUnpacked, samples)
+			t.Run("round trip", assertNil(e))
+
+			var short Encode[testPackedRow, []byte] = func(p testPackedRow) ([]byte, error) {
+				return []byte{p.key}, nil
+			}
+			e = RoundTripUnpack(PackCompose(pack, short), unpackBytes, sameUnpacked, samples)
+			t.Run("decode error", assertEq(errors.Is(e, testErrorInvalidVal), true))
+			t.Run("round trip error", assertEq(errors.Is(e, ErrRoundTrip), true))
+		})
+	})
+}
@@ -6,6 +6,9 @@ import (
 
 // GetByKeyNewDecoded creates a closure which gets a decoded item.
 //
+// The returned closure must not be used concurrently(buf is shared);
+// use GetByKeyNewDecodedPooled instead.
+//
 // # Arguments
 //   - getEncodedByKey: Gets an encoded item.
 //   - decoder: Gets a decoded item from an encoded item.
@@ -34,6 +37,9 @@ func GetByKeyNewDecoded[G, K, E, D any](
 
 // GetByKeysNewUnnested creates a closure which gets unnested items.
 //
+// The unnested items of a packed item are local to a call;
+// the closure is safe for concurrent use if its arguments are and buf is not shared.
+//
 // # Arguments
 //   - getByKey: Gets a packed item by a key.
 //   - unnest: Gets unnested items from a packed item.
@@ -48,7 +54,13 @@ func GetByKeysNewUnnested[G, K, P, F, U any](
 	consumeUnpacked IterConsumer[U],
 ) func(ctx context.Context, keys []K, get G, buf *P, filter *F) error {
 	return func(ctx context.Context, keys []K, con G, buf *P, filter *F) error {
+		var cc cancelCheck = cancelCheckNew(ctx)
 		for _, key := range keys {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+
 			got, e := getByKey(ctx, con, key, buf)
 			if nil != e {
 				return e
@@ -100,6 +112,8 @@ type GetKeys[D, B, F, K any] func(
 
 // WithBucketFilter creates a new GetKeys which gets keys after checking a bucket.
 //
+// The GetKeys is safe for concurrent use if g and checkBucket are.
+//
 // # Arguments
 //   - checkBucket: Must return true if a bucket must be checked.
 func (g GetKeys[D, B, F, K]) WithBucketFilter(
@@ -134,6 +148,9 @@ type GetByKey[D, B, F, K, V any] func(
 
 // GetByKeyDecodedNew creates a new closure which gets a decoded value.
 //
+// The returned closure must not be used concurrently(buf is shared);
+// use GetByKeyDecodedNewPooled instead.
+//
 // # Arguments
 //   - getEncodedByKey: Gets an encoded value.
 //   - decoder: Gets a decoded value from an encoded value.
@@ -190,6 +207,9 @@ type Got2Consumer[D, K, F, B, V any] func(
 
 // GetByKeysNew creates a closure which uses items got using keys.
 //
+// Items are saved to buf given by the caller;
+// the closure is safe for concurrent use if getKeys and getByKey are and buf is not shared.
+//
 // # Arguments
 //   - getKeys: Gets keys for items.
 //   - getByKey: Gets an item by a key.
@@ -209,7 +229,12 @@ func GetByKeysNew[G, K, F, B, V any](
 		if nil != e {
 			return e
 		}
+		var cc cancelCheck = cancelCheckNew(ctx)
 		for _, key := range keys {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
 			got, e := getByKey(ctx, con, bucket, key, buf, filter)
 			if nil != e {
 				return e
@@ -231,6 +256,9 @@ func GetByKeysNew[G, K, F, B, V any](
 
 // GetWithPlanNew creates a closure which get items.
 //
+// The closure only dispatches a call;
+// it is as safe for concurrent use as getByKeys, getDirect and plan.
+//
 // # Arguments
 //   - getByKeys: Gets items using keys(indirect scan).
 //   - getDirect: Gets items(direct scan).
@@ -263,3 +291,63 @@ func GetWithPlanNew[G, K, F, B, V any](
 		)
 	}
 }
+
+// GetByKeysNewUnnestedEach creates a closure like GetByKeysNewUnnested
+// which does not allocate a slice for each packed item.
+//
+// Each call has its own buffer for an unnested item(buf for a packed item is the caller's);
+// the closure is safe for concurrent use if unnest and the other closures are.
+//
+// # Arguments
+//   - getByKey: Gets a packed item by a key.
+//   - unnest: Passes unnested items to a callback.
+//   - filterPacked: Checks if a packed item must be used or not.
+//   - filterUnpacked: Check if an unpacked item must be used or not.
+//   - consumeUnpacked: Uses an unpacked item.
+func GetByKeysNewUnnestedEach[G, K, P, F, U any](
+	getByKey func(ctx context.Context, con G, key K, packed *P) (got bool, e error),
+	unnest UnnestEach[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	filterUnpacked func(unpacked *U, filter *F) (keep bool),
+	consumeUnpacked IterConsumer[U],
+) func(ctx context.Context, keys []K, get G, buf *P, filter *F) error {
+	return func(ctx context.Context, keys []K, con G, buf *P, filter *F) error {
+		var child U
+		each := func(unpacked *U) (stop bool, e error) {
+			var keep bool = filterUnpacked(unpacked, filter)
+			if !keep {
+				return false, nil
+			}
+			return consumeUnpacked(unpacked)
+		}
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for _, key := range keys {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+
+			got, e := getByKey(ctx, con, key, buf)
+			if nil != e {
+				return e
+			}
+			if !got {
+				continue
+			}
+
+			var keepPacked bool = filterPacked(buf, filter)
+			if !keepPacked {
+				continue
+			}
+
+			stop, e := unnest(buf, &child, each)
+			if nil != e {
+				return e
+			}
+			if stop {
+				return nil
+			}
+		}
+		return nil
+	}
+}
@@ -1218,4 +1218,69 @@ func TestIndirect(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("GetByKeysNewUnnestedEach", func(t *testing.T) {
+		t.Parallel()
+
+		getByKey := func(
+			_ context.Context,
+			_con uint8,
+			k testIndirectKey,
+			d *testIndirectDecoded,
+		) (got bool, e error) {
+			d.coarse = uint16(k.serial)
+			d.val = [2]uint64{0x0000000100000002, 0x0000000300000004}
+			return 0 != k.serial, nil
+		}
+
+		var each UnnestEach[testIndirectDecoded, testIndirectUnpacked] = func(
+			d *testIndirectDecoded,
+			buf *testIndirectUnpacked,
+			f func(*testIndirectUnpacked) (bool, error),
+		) (stop bool, e error) {
+			for _, v := range d.val {
+				*buf = testIndirectUnpacked{
+					code:   uint32(v >> 32),
+					id:     uint32(v & 0xffffffff),
+					coarse: d.coarse,
+				}
+				stop, e = f(buf)
+				if nil != e || stop {
+					return true, e
+				}
+			}
+			return false, nil
+		}
+
+		var unpacked []testIndirectUnpacked
+		f := GetByKeysNewUnnestedEach(
+			getByKey,
+			each,
+			func(d *testIndirectDecoded, f *testIndirectFilter) (keep bool) {
+				return d.coarse != f.coarse
+			},
+			func(u *testIndirectUnpacked, f *testIndirectFilter) (keep bool) {
+				return u.code != f.code
+			},
+			func(u *testIndirectUnpacked) (stop bool, e error) {
+				unpacked = append(unpacked, *u)
+				return 3 == len(unpacked), nil
+			},
+		)
+
+		var buf testIndirectDecoded
+		var filter testIndirectFilter = testIndirectFilter{coarse: 2, code: 3}
+		e := f(
+			context.Background(),
+			[]testIndirectKey{{0}, {1}, {2}, {3}, {4}, {5}},
+			0,
+			&buf,
+			&filter,
+		)
+		t.Run("no error", assertNil(e))
+		t.Run("3 items", assertEq(len(unpacked), 3))
+		t.Run("coarse 1", assertEq(unpacked[0].coarse, 1))
+		t.Run("coarse 3", assertEq(unpacked[1].coarse, 3))
+		t.Run("coarse 4", assertEq(unpacked[2].coarse, 4))
+	})
 }
@@ -6,6 +6,9 @@ import (
 
 // Iter2UnpackedNew creates a new closure which gets unpacked items.
 //
+// The buffer for a packed item is created for each call;
+// the closure is safe for concurrent use if its arguments are(an iterator must not be shared).
+//
 // # Arguments
 //   - packed2unpacked: Gets an unpacked item from a packed item.
 //   - hasNext: Checks if an iterator has a next item.
@@ -19,7 +22,13 @@ func Iter2UnpackedNew[I, P, U any](
 ) func(ctx context.Context, iter I) (unpacked []U, e error) {
 	return func(ctx context.Context, iter I) (unpacked []U, e error) {
 		var buf P
+		var cc cancelCheck = cancelCheckNew(ctx)
 		for hasNext(iter) {
+			e = cc.check()
+			if nil != e {
+				return nil, e
+			}
+
 			e = getPacked(iter, &buf)
 			if nil != e {
 				return nil, e
@@ -44,6 +53,8 @@ func Iter2UnpackedNew[I, P, U any](
 
 // Iter2UnpackedWithFilterNew creates a new closure which gets required unpacked items.
 //
+// Like Iter2UnpackedNew, each call has its own buffer for a packed item.
+//
 // # Arguments
 //   - packed2unpacked: Gets an unpacked item from a packed item.
 //   - hasNext: Checks if an iterator has a next item.
@@ -61,7 +72,13 @@ func Iter2UnpackedWithFilterNew[I, P, U, F any](
 ) func(ctx context.Context, iter I, filter *F) (unpacked []U, e error) {
 	return func(ctx context.Context, iter I, filter *F) (unpacked []U, e error) {
 		var buf P
+		var cc cancelCheck = cancelCheckNew(ctx)
 		for hasNext(iter) {
+			e = cc.check()
+			if nil != e {
+				return nil, e
+			}
+
 			e = getPacked(iter, &buf)
 			if nil != e {
 				return nil, e
@@ -146,6 +163,8 @@ func IterConsumerFilterMany[T, F any](
 }
 
 // IterConsumerNewPacked creates a new packed item consumer from an unpacked consumer.
+//
+// The IterConsumer is safe for concurrent use if unpack and consumer are.
 func IterConsumerNewPacked[P, U any](
 	unpack func(packed *P) (unpacked []U, e error),
 	consumer IterConsumer[U],
@@ -171,6 +190,9 @@ func IterConsumerNewPacked[P, U any](
 
 // Iter2ConsumerNewFiltered creates a closure which consumes filtered values.
 //
+// Values are read into buf given by the caller;
+// the closure is safe for concurrent use if its arguments are and buf is not shared.
+//
 // # Arguments
 //   - iterNext: Checks if an iterator has a next item or not.
 //   - iterGet: Gets a next value.
@@ -185,7 +207,12 @@ func Iter2ConsumerNewFiltered[I, T, F any](
 	consumer IterConsumer[T],
 ) func(ctx context.Context, iter I, buf *T, filter *F) error {
 	return func(ctx context.Context, iter I, buf *T, filter *F) (e error) {
+		var cc cancelCheck = cancelCheckNew(ctx)
 		for iterNext(iter) {
+			e = cc.check()
+			if nil != e {
+				return
+			}
 			e = iterGet(iter, buf)
 			if nil != e {
 				return
@@ -208,6 +235,9 @@ func Iter2ConsumerNewFiltered[I, T, F any](
 
 // Iter2ConsumerNewUnpacked creates a closure which consumes an unpacked items after filtering.
 //
+// An unpacked item is local to a call; the closure is safe for concurrent use
+// if its arguments are and each goroutine has its own buf.
+//
 // # Arguments
 //   - iterNext: Checks if an iterator has a next item or not.
 //   - iterGet: Gets a packed item.
@@ -226,7 +256,13 @@ func Iter2ConsumerNewUnpacked[I, P, F, U any](
 	consumer IterConsumer[U],
 ) func(ctx context.Context, iter I, buf *P, filter *F) error {
 	return func(ctx context.Context, iter I, buf *P, filter *F) (e error) {
+		var cc cancelCheck = cancelCheckNew(ctx)
 		for iterNext(iter) {
+			e = cc.check()
+			if nil != e {
+				return
+			}
+
 			e = iterGet(iter, buf)
 			if nil != e {
 				return
@@ -264,6 +300,8 @@ type IterConsumerFiltered[T, F any] func(value *T, filter *F) (stop bool, e erro
 
 // ConsumerUnpackedNew creates a new IterConsumerFiltered which consumes packed items.
 //
+// The consumer keeps no state; it is safe for concurrent use if its arguments are.
+//
 // # Arguments
 //   - unpackedConsumer: Uses unpacked items.
 //   - packed2unpacked: Gets unpacked items from a packed item.
@@ -296,6 +334,15 @@ func ConsumerUnpackedNew[P, U, F any](
 	}
 }
 
+// ConsumerDecodedNew creates a new IterConsumerFiltered which consumes encoded items.
+//
+// A decoded item is local to a call;
+// the consumer is safe for concurrent use if decodedConsumer, decoder and filterEncoded are.
+//
+// # Arguments
+//   - decodedConsumer: Uses decoded items.
+//   - decoder: Gets a decoded item from an encoded item.
+//   - filterEncoded: Checks if an encoded item must be used or not.
 func ConsumerDecodedNew[E, D, F any](
 	decodedConsumer IterConsumerFiltered[D, F],
 	decoder func(encoded *E) (decoded D, e error),
@@ -316,6 +363,8 @@ func ConsumerDecodedNew[E, D, F any](
 
 // IterConsumeManyFilteredNew creates a closure which consumes an iterator.
 //
+// The closure keeps no state; concurrent calls must use their own iterators and buffers.
+//
 // # Arguments
 //   - iterNext: Checks if an iterator has a next item or not.
 //   - iterGet: Gets a value from an iterator.
@@ -342,3 +391,40 @@ func IterConsumeManyFilteredNew[I, T, F any](
 		return false, iterErr(iter)
 	}
 }
+
+// ConsumerDecodedNewWithPolicy creates a closure which creates an IterConsumerFiltered
+// for a scan which uses an ErrorPolicy.
+//
+// A new consumer must be created for each scan(bucket);
+// the index of an ItemError is the number of encoded items seen by the consumer.
+// The returned closure is safe for concurrent use; a consumer is not.
+//
+// # Arguments
+//   - decodedConsumer: Uses decoded items.
+//   - decoder: Gets a decoded item from an encoded item.
+//   - filterEncoded: Checks if an encoded item must be used or not.
+//   - policy: Decides what to do with an item which can not be decoded.
+func ConsumerDecodedNewWithPolicy[E, D, F any](
+	decodedConsumer IterConsumerFiltered[D, F],
+	decoder func(encoded *E) (decoded D, e error),
+	filterEncoded func(encoded *E, filter *F) (keep bool),
+	policy ErrorPolicy,
+) func(bucket Bucket, report *ErrorReport) IterConsumerFiltered[E, F] {
+	return func(bucket Bucket, report *ErrorReport) IterConsumerFiltered[E, F] {
+		var index int = 0
+		return func(encoded *E, filter *F) (stop bool, e error) {
+			var ix int = index
+			index += 1
+			var keep bool = filterEncoded(encoded, filter)
+			if !keep {
+				return false, nil
+			}
+			decoded, e := decoder(encoded)
+			if nil != e {
+				e = report.Handle(policy, ItemErrorNew(bucket, ix, nil, e))
+				return nil != e, e
+			}
+			return decodedConsumer(&decoded, filter)
+		}
+	}
+}
@@ -1368,4 +1368,82 @@ func TestIter(t *testing.T) {
 			t.Run("single item", assertEq(len(decodedItems), 1))
 		})
 	})
+
+	t.Run("ConsumerDecodedNewWithPolicy", func(t *testing.T) {
+		t.Parallel()
+
+		var decodedItems []testIterDecoded
+		var decodedConsumer IterConsumerFiltered[testIterDecoded, testIterFilter] = func(
+			val *testIterDecoded,
+			f *testIterFilter,
+		) (stop bool, e error) {
+			decodedItems = append(decodedItems, *val)
+			return
+		}
+		var invalid bool = false
+		decoder := func(encoded *testIterPacked) (d testIterDecoded, e error) {
+			if invalid {
+				return d, testErrorInvalidVal
+			}
+			return
+		}
+		filterEncoded := func(e *testIterPacked, f *testIterFilter) (keep bool) { return true }
+
+		t.Run("skip collect", func(t *testing.T) {
+			consumerNew := ConsumerDecodedNewWithPolicy(
+				decodedConsumer,
+				decoder,
+				filterEncoded,
+				ErrorPolicySkipCollect,
+			)
+
+			var report ErrorReport
+			var encodedConsumer IterConsumerFiltered[
+				testIterPacked,
+				testIterFilter,
+			] = consumerNew(BucketNew("b1"), &report)
+
+			var encoded testIterPacked = testIterPacked{}
+			var f testIterFilter = testIterFilter{}
+
+			_, _ = encodedConsumer(&encoded, &f)
+			invalid = true
+			stop, e := encodedConsumer(&encoded, &f)
+			t.Run("no error", assertNil(e))
+			t.Run("non stop", assertEq(stop, false))
+			t.Run("single item", assertEq(len(decodedItems), 1))
+			t.Run("single error", assertEq(len(report.Errors()), 1))
+			t.Run("index", assertEq(report.Errors()[0].Index(), 1))
+
+			// the second scan must not continue the index of the first scan
+			var second ErrorReport
+			encodedConsumer = consumerNew(BucketNew("b2"), &second)
+			_, e = encodedConsumer(&encoded, &f)
+			t.Run("no error", assertNil(e))
+			t.Run("single error", assertEq(len(second.Errors()), 1))
+			t.Run("index reset", assertEq(second.Errors()[0].Index(), 0))
+			t.Run("bucket", assertEq(second.Errors()[0].Bucket().AsString(), "b2"))
+			invalid = false
+		})
+
+		t.Run("fail fast", func(t *testing.T) {
+			invalid = true
+			var report ErrorReport
+			var encodedConsumer IterConsumerFiltered[
+				testIterPacked,
+				testIterFilter,
+			] = ConsumerDecodedNewWithPolicy(
+				decodedConsumer,
+				decoder,
+				filterEncoded,
+				ErrorPolicyFailFast,
+			)(BucketNew(""), &report)
+
+			var encoded testIterPacked = testIterPacked{}
+			var f testIterFilter = testIterFilter{}
+			stop, e := encodedConsumer(&encoded, &f)
+			t.Run("error", assertEq(nil != e, true))
+			t.Run("stop", assertEq(stop, true))
+		})
+	})
 }
@@ -0,0 +1,135 @@
+package local
+
+import (
+	"context"
+)
+
+// GetKeysPage must get a page of keys from a bucket using a filter.
+//
+// # Arguments
+//   - ctx: A context.
+//   - con: A data store which may contain keys.
+//   - bucket: A bucket which may contain keys.
+//   - filter: A filter to minimize keys to get.
+//   - cursor: The position of the page(the zero value means the first page).
+//
+// # Return value
+//   - keys: The keys of the page(the same cursor must get the same keys).
+//   - next: The position of the next page.
+//   - more: Must be true if the next page may exist.
+type GetKeysPage[D, B, F, K, C any] func(
+	ctx context.Context,
+	con D,
+	bucket *B,
+	filter *F,
+	cursor C,
+) (keys []K, next C, more bool, e error)
+
+// KeysPosition is the position of a paginated scan.
+type KeysPosition[C any] struct {
+	// Page is the cursor of the current page.
+	Page C
+
+	// Offset is the number of processed keys of the current page.
+	Offset int
+
+	// Done is true if all pages are processed.
+	Done bool
+}
+
+// GetByKeysNewPaged creates a closure like GetByKeysNew which uses keys page by page.
+//
+// The position is updated as keys are processed;
+// a scan stopped(or failed) can be resumed from the position.
+// An item whose getByKey or consumer fails is processed again on resume.
+// A position belongs to a single scan; the closure keeps no other state
+// and is safe for concurrent use if getPage and getByKey are(pos and buf are not shared).
+//
+// # Arguments
+//   - getPage: Gets a page of keys.
+//   - getByKey: Gets an item by a key.
+func GetByKeysNewPaged[G, K, F, B, V, C any](
+	getPage GetKeysPage[G, B, F, K, C],
+	getByKey GetByKey[G, B, F, K, V],
+) func(
+	ctx context.Context,
+	con G,
+	bucket *B,
+	filter *F,
+	pos *KeysPosition[C],
+	buf *V,
+	consumer func(val *V, filter *F) (stop bool, e error),
+) error {
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		filter *F,
+		pos *KeysPosition[C],
+		buf *V,
+		consumer func(val *V, filter *F) (stop bool, e error),
+	) error {
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for !pos.Done {
+			keys, next, more, e := getPage(ctx, con, bucket, filter, pos.Page)
+			if nil != e {
+				return e
+			}
+			for pos.Offset < len(keys) {
+				e = cc.check()
+				if nil != e {
+					return e
+				}
+				got, e := getByKey(ctx, con, bucket, keys[pos.Offset], buf, filter)
+				if nil != e {
+					return e
+				}
+				if !got {
+					pos.Offset += 1
+					continue
+				}
+				stop, e := consumer(buf, filter)
+				if nil != e {
+					return e
+				}
+				pos.Offset += 1
+				if stop {
+					return nil
+				}
+			}
+			if !more {
+				pos.Done = true
+				return nil
+			}
+			pos.Page = next
+			pos.Offset = 0
+		}
+		return nil
+	}
+}
+
+// GetByKeysNewPagedAll creates a Got2Consumer which uses all pages from the first page.
+//
+// A fresh position is used for each call
+// so that the Got2Consumer can be shared like GetByKeysNewPaged.
+//
+// # Arguments
+//   - getPage: Gets a page of keys.
+//   - getByKey: Gets an item by a key.
+func GetByKeysNewPagedAll[G, K, F, B, V, C any](
+	getPage GetKeysPage[G, B, F, K, C],
+	getByKey GetByKey[G, B, F, K, V],
+) Got2Consumer[G, K, F, B, V] {
+	paged := GetByKeysNewPaged(getPage, getByKey)
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		filter *F,
+		buf *V,
+		consumer func(val *V, filter *F) (stop bool, e error),
+	) error {
+		var pos KeysPosition[C]
+		return paged(ctx, con, bucket, filter, &pos, buf, consumer)
+	}
+}
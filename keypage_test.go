@@ -0,0 +1,114 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestKeyPage(t *testing.T) {
+	t.Parallel()
+
+	// 3 keys per page; the cursor is the first key of a page.
+	var pages int
+	var getPage GetKeysPage[uint8, string, uint8, int, int] = func(
+		_ context.Context,
+		_ uint8,
+		_ *string,
+		_ *uint8,
+		cursor int,
+	) (keys []int, next int, more bool, e error) {
+		pages += 1
+		for key := cursor; key < cursor+3 && key < 10; key++ {
+			keys = append(keys, key)
+		}
+		return keys, cursor + 3, cursor+3 < 10, nil
+	}
+
+	var getByKey GetByKey[uint8, string, uint8, int, int] = func(
+		_ context.Context,
+		_ uint8,
+		_ *string,
+		key int,
+		val *int,
+		_ *uint8,
+	) (got bool, e error) {
+		if 7 == key {
+			return false, nil
+		}
+		*val = key
+		return true, nil
+	}
+
+	t.Run("GetByKeysNewPagedAll", func(t *testing.T) {
+		pages = 0
+		var vals []int
+		f := GetByKeysNewPagedAll(getPage, getByKey)
+		var buf int
+		e := f(context.Background(), 0, nil, nil, &buf, func(val *int, _ *uint8) (bool, error) {
+			vals = append(vals, *val)
+			return false, nil
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("4 pages", assertEq(pages, 4))
+		t.Run("9 items", assertEq(len(vals), 9))
+		t.Run("last", assertEq(vals[8], 9))
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		pages = 0
+		var vals []int
+		f := GetByKeysNewPaged(getPage, getByKey)
+		var pos KeysPosition[int]
+		var buf int
+		consumer := func(val *int, _ *uint8) (bool, error) {
+			vals = append(vals, *val)
+			return 0 == len(vals)%4, nil
+		}
+
+		e := f(context.Background(), 0, nil, nil, &pos, &buf, consumer)
+		t.Run("no error", assertNil(e))
+		t.Run("4 items", assertEq(len(vals), 4))
+		t.Run("position", assertEq(pos, KeysPosition[int]{Page: 3, Offset: 1}))
+
+		e = f(context.Background(), 0, nil, nil, &pos, &buf, consumer)
+		t.Run("no error", assertNil(e))
+		t.Run("8 items", assertEq(len(vals), 8))
+		t.Run("continued", assertEq(vals[4], 4))
+
+		e = f(context.Background(), 0, nil, nil, &pos, &buf, consumer)
+		t.Run("no error", assertNil(e))
+		t.Run("9 items", assertEq(len(vals), 9))
+		t.Run("done", assertEq(pos.Done, true))
+
+		var before int = pages
+		e = f(context.Background(), 0, nil, nil, &pos, &buf, consumer)
+		t.Run("no error", assertNil(e))
+		t.Run("no page", assertEq(pages, before))
+	})
+
+	t.Run("retry failed item", func(t *testing.T) {
+		var vals []int
+		var fail bool = true
+		f := GetByKeysNewPaged(getPage, getByKey)
+		var pos KeysPosition[int]
+		var buf int
+		consumer := func(val *int, _ *uint8) (bool, error) {
+			if 4 == *val && fail {
+				fail = false
+				return true, testErrorInvalidKey
+			}
+			vals = append(vals, *val)
+			return false, nil
+		}
+
+		e := f(context.Background(), 0, nil, nil, &pos, &buf, consumer)
+		t.Run("error", assertEq(errors.Is(e, testErrorInvalidKey), true))
+		t.Run("position", assertEq(pos, KeysPosition[int]{Page: 3, Offset: 1}))
+
+		e = f(context.Background(), 0, nil, nil, &pos, &buf, consumer)
+		t.Run("no error", assertNil(e))
+		t.Run("9 items", assertEq(len(vals), 9))
+		t.Run("retried", assertEq(vals[4], 4))
+	})
+}
@@ -0,0 +1,170 @@
+package local
+
+import (
+	"context"
+)
+
+// Sorted creates a new GetKeys which gets sorted keys without duplicates.
+//
+// The keys of each call are copied before sorting;
+// the GetKeys is safe for concurrent use if g and less are.
+//
+// # Arguments
+//   - less: Checks if a key must be placed before another key.
+func (g GetKeys[D, B, F, K]) Sorted(less func(a, b K) bool) GetKeys[D, B, F, K] {
+	return GetKeysUnion(KeySetOpsOrderedNew(less), false, g)
+}
+
+// KeyRange contains consecutive keys(inclusive).
+type KeyRange[K any] struct {
+	First K
+	Last  K
+}
+
+// KeyRangesNew coalesces runs of consecutive keys into ranges.
+//
+// # Arguments
+//   - sorted: Sorted keys without duplicates.
+//   - less: Checks if a key must be placed before another key.
+//   - successor: Gets the next key of a key.
+func KeyRangesNew[K any](
+	sorted []K,
+	less func(a, b K) bool,
+	successor func(key K) (next K),
+) (ranges []KeyRange[K]) {
+	for ix, key := range sorted {
+		if 0 < ix {
+			var last *KeyRange[K] = &ranges[len(ranges)-1]
+			var next K = successor(last.Last)
+			var consecutive bool = !less(next, key) && !less(key, next)
+			if consecutive {
+				last.Last = key
+				continue
+			}
+		}
+		ranges = append(ranges, KeyRange[K]{First: key, Last: key})
+	}
+	return
+}
+
+// GetByKeyRange must pass items in a range to a callback in key order.
+//
+// # Arguments
+//   - ctx: A context.
+//   - con: A data store connection.
+//   - bucket: A bucket which may have items to get.
+//   - r: The keys of items to get(missing items must be ignored).
+//   - filter: The filter which may be used to get or skip getting items.
+//   - found: Uses an item(the item may be reused after found returns); returns true to stop.
+type GetByKeyRange[D, B, F, K, V any] func(
+	ctx context.Context,
+	con D,
+	bucket *B,
+	r KeyRange[K],
+	filter *F,
+	found func(val *V) (stop bool, e error),
+) error
+
+// GetByKeyRangeFromKey creates a GetByKeyRange which calls getByKey for each key in a range.
+//
+// The buffer for an item is created for each range;
+// the GetByKeyRange is safe for concurrent use if getByKey, less and successor are.
+//
+// # Arguments
+//   - getByKey: Gets an item by a key.
+//   - less: Checks if a key must be placed before another key.
+//   - successor: Gets the next key of a key.
+func GetByKeyRangeFromKey[D, B, F, K, V any](
+	getByKey GetByKey[D, B, F, K, V],
+	less func(a, b K) bool,
+	successor func(key K) (next K),
+) GetByKeyRange[D, B, F, K, V] {
+	return func(
+		ctx context.Context,
+		con D,
+		bucket *B,
+		r KeyRange[K],
+		filter *F,
+		found func(val *V) (stop bool, e error),
+	) error {
+		var buf V
+		var key K = r.First
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+			got, e := getByKey(ctx, con, bucket, key, &buf, filter)
+			if nil != e {
+				return e
+			}
+			if got {
+				stop, e := found(&buf)
+				if nil != e || stop {
+					return e
+				}
+			}
+			// the successor of the last key may overflow
+			if !less(key, r.Last) {
+				return nil
+			}
+			key = successor(key)
+		}
+	}
+}
+
+// GetByKeysNewRanged creates a closure like GetByKeysNew
+// which gets items by ranges of consecutive keys.
+//
+// Keys are deduplicated and sorted before fetch.
+// Each call sorts its own keys; with a buf for each goroutine
+// the closure is safe for concurrent use if getKeys, getRange and the key functions are.
+//
+// # Arguments
+//   - getKeys: Gets keys for items.
+//   - less: Checks if a key must be placed before another key.
+//   - successor: Gets the next key of a key.
+//   - getRange: Gets items in a range.
+func GetByKeysNewRanged[G, K, F, B, V any](
+	getKeys GetKeys[G, B, F, K],
+	less func(a, b K) bool,
+	successor func(key K) (next K),
+	getRange GetByKeyRange[G, B, F, K, V],
+) Got2Consumer[G, K, F, B, V] {
+	var sorted GetKeys[G, B, F, K] = getKeys.Sorted(less)
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		filter *F,
+		buf *V,
+		consumer func(val *V, filter *F) (stop bool, e error),
+	) error {
+		keys, e := sorted(ctx, con, bucket, filter)
+		if nil != e {
+			return e
+		}
+		var stopped bool = false
+		found := func(val *V) (stop bool, e error) {
+			*buf = *val
+			stopped, e = consumer(buf, filter)
+			return stopped, e
+		}
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for _, r := range KeyRangesNew(keys, less, successor) {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+			e = getRange(ctx, con, bucket, r, filter, found)
+			if nil != e {
+				return e
+			}
+			if stopped {
+				return nil
+			}
+		}
+		return nil
+	}
+}
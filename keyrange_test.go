@@ -0,0 +1,193 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestKeyRange(t *testing.T) {
+	t.Parallel()
+
+	var less func(a, b uint8) bool = func(a, b uint8) bool { return a < b }
+	var successor func(key uint8) uint8 = func(key uint8) uint8 { return key + 1 }
+
+	t.Run("KeyRangesNew", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("empty", assertEq(len(KeyRangesNew(nil, less, successor)), 0))
+
+		var ranges []KeyRange[uint8] = KeyRangesNew(
+			[]uint8{1, 2, 3, 5, 7, 8, 254, 255},
+			less,
+			successor,
+		)
+		t.Run("4 ranges", assertEq(len(ranges), 4))
+		t.Run("first", assertEq(ranges[0], KeyRange[uint8]{First: 1, Last: 3}))
+		t.Run("single", assertEq(ranges[1], KeyRange[uint8]{First: 5, Last: 5}))
+		t.Run("last", assertEq(ranges[3], KeyRange[uint8]{First: 254, Last: 255}))
+	})
+
+	t.Run("Sorted", func(t *testing.T) {
+		t.Parallel()
+
+		var g GetKeys[uint8, string, uint8, uint8] = testKeysetSource8(7, 3, 3, 1, 7, 2)
+		keys, e := g.Sorted(less)(context.Background(), 0, nil, nil)
+		t.Run("no error", assertNil(e))
+		t.Run("4 keys", assertEq(len(keys), 4))
+		t.Run("first", assertEq(keys[0], 1))
+		t.Run("second", assertEq(keys[1], 2))
+		t.Run("last", assertEq(keys[3], 7))
+	})
+
+	var store map[uint8]uint8 = map[uint8]uint8{
+		1: 10, 2: 20, 3: 30, 5: 50, 7: 70, 8: 80, 255: 55,
+	}
+
+	var rangeCalls int
+	var getRange GetByKeyRange[map[uint8]uint8, string, uint8, uint8, uint8] = func(
+		_ context.Context,
+		con map[uint8]uint8,
+		_ *string,
+		r KeyRange[uint8],
+		_ *uint8,
+		found func(val *uint8) (stop bool, e error),
+	) error {
+		rangeCalls += 1
+		for key := int(r.First); key <= int(r.Last); key++ {
+			val, ok := con[uint8(key)]
+			if !ok {
+				continue
+			}
+			stop, e := found(&val)
+			if nil != e || stop {
+				return e
+			}
+		}
+		return nil
+	}
+
+	var getKeys GetKeys[map[uint8]uint8, string, uint8, uint8] = func(
+		_ context.Context,
+		_ map[uint8]uint8,
+		_ *string,
+		_ *uint8,
+	) ([]uint8, error) {
+		return []uint8{8, 3, 1, 2, 7, 4, 3, 255}, nil
+	}
+
+	t.Run("GetByKeyRangeFromKey cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var fetched int = 0
+		f := GetByKeyRangeFromKey(
+			GetByKey[uint8, string, uint8, int, int](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				key int,
+				val *int,
+				_ *uint8,
+			) (bool, error) {
+				fetched += 1
+				*val = key
+				return true, nil
+			}),
+			func(a, b int) bool { return a < b },
+			func(key int) int { return key + 1 },
+		)
+		var r KeyRange[int] = KeyRange[int]{First: 0, Last: 1 << 20}
+		e := f(ctx, 0, nil, r, nil, func(val *int) (bool, error) {
+			if 2 == *val {
+				cancel()
+			}
+			return false, nil
+		})
+		t.Run("cancelled", assertEq(errors.Is(e, context.Canceled), true))
+		t.Run("stopped promptly", assertEq(fetched, 3))
+	})
+
+	t.Run("GetByKeysNewRanged", func(t *testing.T) {
+		t.Run("all", func(t *testing.T) {
+			rangeCalls = 0
+			var vals []uint8
+			f := GetByKeysNewRanged(getKeys, less, successor, getRange)
+			var buf uint8
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				val *uint8,
+				_ *uint8,
+			) (stop bool, e error) {
+				vals = append(vals, *val)
+				return false, nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("3 ranges", assertEq(rangeCalls, 3))
+			t.Run("6 items", assertEq(len(vals), 6))
+			t.Run("first", assertEq(vals[0], 10))
+			t.Run("last", assertEq(vals[5], 55))
+		})
+
+		t.Run("stop", func(t *testing.T) {
+			rangeCalls = 0
+			var vals []uint8
+			f := GetByKeysNewRanged(getKeys, less, successor, getRange)
+			var buf uint8
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				val *uint8,
+				_ *uint8,
+			) (stop bool, e error) {
+				vals = append(vals, *val)
+				return 2 == len(vals), nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("single range", assertEq(rangeCalls, 1))
+			t.Run("2 items", assertEq(len(vals), 2))
+		})
+
+		t.Run("from key", func(t *testing.T) {
+			var calls int = 0
+			var vals []uint8
+			f := GetByKeysNewRanged(
+				getKeys,
+				less,
+				successor,
+				GetByKeyRangeFromKey(
+					GetByKey[map[uint8]uint8, string, uint8, uint8, uint8](func(
+						_ context.Context,
+						con map[uint8]uint8,
+						_ *string,
+						key uint8,
+						val *uint8,
+						_ *uint8,
+					) (got bool, e error) {
+						calls += 1
+						*val, got = con[key]
+						return got, nil
+					}),
+					less,
+					successor,
+				),
+			)
+			var buf uint8
+			e := f(context.Background(), store, nil, nil, &buf, func(
+				val *uint8,
+				_ *uint8,
+			) (stop bool, e error) {
+				vals = append(vals, *val)
+				return false, nil
+			})
+			t.Run("no error", assertNil(e))
+			t.Run("7 unique keys", assertEq(calls, 7))
+			t.Run("6 items", assertEq(len(vals), 6))
+			t.Run("max key", assertEq(vals[5], 55))
+		})
+	})
+}
+
+func testKeysetSource8(keys ...uint8) GetKeys[uint8, string, uint8, uint8] {
+	return func(_ context.Context, _ uint8, _ *string, _ *uint8) ([]uint8, error) {
+		return keys, nil
+	}
+}
@@ -0,0 +1,270 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// KeySetOps combines key sets.
+//
+// Results contain no duplicates.
+type KeySetOps[K any] struct {
+	intersect func(a, b []K) []K
+	union     func(a, b []K) []K
+	subtract  func(a, b []K) []K
+}
+
+// KeySetOpsHashNew creates a KeySetOps which uses hashes(identities) of keys.
+//
+// Results keep the order of the first set(and then the second set for union).
+// Sets are built for each operation; the KeySetOps is safe for concurrent use if hash is.
+//
+// # Arguments
+//   - hash: Gets the identity of a key.
+func KeySetOpsHashNew[K any, H comparable](hash func(key K) H) KeySetOps[K] {
+	toSet := func(keys []K) map[H]struct{} {
+		var s map[H]struct{} = make(map[H]struct{}, len(keys))
+		for _, key := range keys {
+			s[hash(key)] = struct{}{}
+		}
+		return s
+	}
+	appendUnique := func(dst []K, seen map[H]struct{}, keys []K, keep func(h H) bool) []K {
+		for _, key := range keys {
+			var h H = hash(key)
+			_, dup := seen[h]
+			if dup || !keep(h) {
+				continue
+			}
+			seen[h] = struct{}{}
+			dst = append(dst, key)
+		}
+		return dst
+	}
+	return KeySetOps[K]{
+		intersect: func(a, b []K) []K {
+			var bs map[H]struct{} = toSet(b)
+			return appendUnique(nil, make(map[H]struct{}), a, func(h H) bool {
+				_, found := bs[h]
+				return found
+			})
+		},
+		union: func(a, b []K) []K {
+			var seen map[H]struct{} = make(map[H]struct{}, len(a)+len(b))
+			all := func(_ H) bool { return true }
+			return appendUnique(appendUnique(nil, seen, a, all), seen, b, all)
+		},
+		subtract: func(a, b []K) []K {
+			var bs map[H]struct{} = toSet(b)
+			return appendUnique(nil, make(map[H]struct{}), a, func(h H) bool {
+				_, found := bs[h]
+				return !found
+			})
+		},
+	}
+}
+
+// KeySetOpsOrderedNew creates a KeySetOps which uses the order of keys.
+//
+// Results are sorted.
+// Inputs are copied before sorting so that the KeySetOps is safe for concurrent use if less is.
+//
+// # Arguments
+//   - less: Checks if a key must be placed before another key.
+func KeySetOpsOrderedNew[K any](less func(a, b K) bool) KeySetOps[K] {
+	sorted := func(keys []K) []K {
+		var s []K = append([]K(nil), keys...)
+		sort.Slice(s, func(i, j int) bool { return less(s[i], s[j]) })
+		var uniq []K = s[:0]
+		for ix, key := range s {
+			if 0 < ix && !less(s[ix-1], key) {
+				continue
+			}
+			uniq = append(uniq, key)
+		}
+		return uniq
+	}
+	// merge passes keys of sorted sets; inA/inB are true if the key is in the set.
+	merge := func(a, b []K, keep func(inA, inB bool) bool) (merged []K) {
+		a, b = sorted(a), sorted(b)
+		var i, j int = 0, 0
+		for i < len(a) || j < len(b) {
+			switch {
+			case len(b) <= j || (i < len(a) && less(a[i], b[j])):
+				if keep(true, false) {
+					merged = append(merged, a[i])
+				}
+				i += 1
+			case len(a) <= i || less(b[j], a[i]):
+				if keep(false, true) {
+					merged = append(merged, b[j])
+				}
+				j += 1
+			default:
+				if keep(true, true) {
+					merged = append(merged, a[i])
+				}
+				i += 1
+				j += 1
+			}
+		}
+		return
+	}
+	return KeySetOps[K]{
+		intersect: func(a, b []K) []K {
+			return merge(a, b, func(inA, inB bool) bool { return inA && inB })
+		},
+		union: func(a, b []K) []K {
+			return merge(a, b, func(_, _ bool) bool { return true })
+		},
+		subtract: func(a, b []K) []K {
+			return merge(a, b, func(inA, inB bool) bool { return inA && !inB })
+		},
+	}
+}
+
+func getKeysAll[D, B, F, K any](
+	ctx context.Context,
+	con D,
+	bucket *B,
+	filter *F,
+	sources []GetKeys[D, B, F, K],
+) (sets [][]K, e error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sets = make([][]K, len(sources))
+	var errs []error = make([]error, len(sources))
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for ix := range sources {
+		go func(ix int) {
+			defer wg.Done()
+			sets[ix], errs[ix] = sources[ix](ctx, con, bucket, filter)
+			if nil != errs[ix] {
+				cancel()
+			}
+		}(ix)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if nil != err && !errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	}
+	for _, err := range errs {
+		if nil != err {
+			return nil, err
+		}
+	}
+	return sets, nil
+}
+
+// getKeysCombined gets keys from sources and combines them.
+//
+// Sequential scans stop when done returns true for the keys got so far.
+func getKeysCombined[D, B, F, K any](
+	ops KeySetOps[K],
+	combine func(a, b []K) []K,
+	done func(keys []K) bool,
+	concurrent bool,
+	sources []GetKeys[D, B, F, K],
+) GetKeys[D, B, F, K] {
+	return func(ctx context.Context, con D, bucket *B, filter *F) (keys []K, e error) {
+		if 0 == len(sources) {
+			return nil, nil
+		}
+		if concurrent {
+			sets, e := getKeysAll(ctx, con, bucket, filter, sources)
+			if nil != e {
+				return nil, e
+			}
+			keys = sets[0]
+			if 1 == len(sets) {
+				return ops.union(keys, nil), nil
+			}
+			for _, set := range sets[1:] {
+				keys = combine(keys, set)
+			}
+			return keys, nil
+		}
+		keys, e = sources[0](ctx, con, bucket, filter)
+		if nil != e {
+			return nil, e
+		}
+		if 1 == len(sources) {
+			return ops.union(keys, nil), nil
+		}
+		for _, source := range sources[1:] {
+			if done(keys) {
+				return keys, nil
+			}
+			set, e := source(ctx, con, bucket, filter)
+			if nil != e {
+				return nil, e
+			}
+			keys = combine(keys, set)
+		}
+		return keys, nil
+	}
+}
+
+func keysEmpty[K any](keys []K) bool { return 0 == len(keys) }
+
+func keysNever[K any](_ []K) bool { return false }
+
+// GetKeysIntersect creates a GetKeys which gets keys found by all sources.
+//
+// A sequential scan skips remaining sources when no key is left.
+// The GetKeys is safe for concurrent use if the sources are;
+// with concurrent, the sources of a call also run at once.
+//
+// # Arguments
+//   - ops: Combines key sets.
+//   - concurrent: Runs sources concurrently if true.
+//   - sources: Get keys(e.g, secondary indexes).
+func GetKeysIntersect[D, B, F, K any](
+	ops KeySetOps[K],
+	concurrent bool,
+	sources ...GetKeys[D, B, F, K],
+) GetKeys[D, B, F, K] {
+	return getKeysCombined(ops, ops.intersect, keysEmpty[K], concurrent, sources)
+}
+
+// GetKeysUnion creates a GetKeys which gets keys found by any source.
+//
+// Like GetKeysIntersect, key sets are combined for each call.
+//
+// # Arguments
+//   - ops: Combines key sets.
+//   - concurrent: Runs sources concurrently if true.
+//   - sources: Get keys(e.g, secondary indexes).
+func GetKeysUnion[D, B, F, K any](
+	ops KeySetOps[K],
+	concurrent bool,
+	sources ...GetKeys[D, B, F, K],
+) GetKeys[D, B, F, K] {
+	return getKeysCombined(ops, ops.union, keysNever[K], concurrent, sources)
+}
+
+// GetKeysSubtract creates a GetKeys which gets keys found by base but not by others.
+//
+// A sequential scan skips remaining sources when no key is left.
+// Nothing is shared between calls except base and others.
+//
+// # Arguments
+//   - ops: Combines key sets.
+//   - concurrent: Runs sources concurrently if true.
+//   - base: Gets keys.
+//   - others: Get keys to be removed.
+func GetKeysSubtract[D, B, F, K any](
+	ops KeySetOps[K],
+	concurrent bool,
+	base GetKeys[D, B, F, K],
+	others ...GetKeys[D, B, F, K],
+) GetKeys[D, B, F, K] {
+	var sources []GetKeys[D, B, F, K] = append([]GetKeys[D, B, F, K]{base}, others...)
+	return getKeysCombined(ops, ops.subtract, keysEmpty[K], concurrent, sources)
+}
@@ -0,0 +1,133 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func testKeysetSource(keys ...int) GetKeys[uint8, string, uint8, int] {
+	return func(_ context.Context, _ uint8, _ *string, _ *uint8) ([]int, error) {
+		return keys, nil
+	}
+}
+
+func testKeysetJoin(keys []int) (joined int) {
+	for _, key := range keys {
+		joined = joined*10 + key
+	}
+	return
+}
+
+func TestKeyset(t *testing.T) {
+	t.Parallel()
+
+	var hash KeySetOps[int] = KeySetOpsHashNew(func(key int) int { return key })
+	var ordered KeySetOps[int] = KeySetOpsOrderedNew(func(a, b int) bool { return a < b })
+
+	var a GetKeys[uint8, string, uint8, int] = testKeysetSource(5, 1, 3, 7, 3, 9)
+	var b GetKeys[uint8, string, uint8, int] = testKeysetSource(9, 3, 2, 5)
+	var c GetKeys[uint8, string, uint8, int] = testKeysetSource(3, 8, 9)
+
+	var tests = []struct {
+		name     string
+		getKeys  GetKeys[uint8, string, uint8, int]
+		expected int
+	}{
+		{"hash intersect", GetKeysIntersect(hash, false, a, b, c), 39},
+		{"hash intersect concurrent", GetKeysIntersect(hash, true, a, b, c), 39},
+		{"hash union", GetKeysUnion(hash, false, a, b), 513792},
+		{"hash union concurrent", GetKeysUnion(hash, true, a, b), 513792},
+		{"hash subtract", GetKeysSubtract(hash, false, a, b, c), 17},
+		{"hash single", GetKeysIntersect(hash, false, a), 51379},
+		{"ordered intersect", GetKeysIntersect(ordered, false, a, b, c), 39},
+		{"ordered union", GetKeysUnion(ordered, true, a, b), 123579},
+		{"ordered subtract", GetKeysSubtract(ordered, true, a, b, c), 17},
+		{"ordered single", GetKeysUnion(ordered, false, a), 13579},
+		{"no sources", GetKeysUnion[uint8, string, uint8](hash, false), 0},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			keys, e := test.getKeys(context.Background(), 0, nil, nil)
+			t.Run("no error", assertNil(e))
+			t.Run("keys", assertEq(testKeysetJoin(keys), test.expected))
+		})
+	}
+
+	t.Run("sequential intersect skips sources", func(t *testing.T) {
+		t.Parallel()
+
+		var called int32 = 0
+		var counted GetKeys[uint8, string, uint8, int] = func(
+			_ context.Context,
+			_ uint8,
+			_ *string,
+			_ *uint8,
+		) ([]int, error) {
+			atomic.AddInt32(&called, 1)
+			return []int{1}, nil
+		}
+		keys, e := GetKeysIntersect(hash, false, testKeysetSource(2), testKeysetSource(3), counted)(
+			context.Background(), 0, nil, nil,
+		)
+		t.Run("no error", assertNil(e))
+		t.Run("no keys", assertEq(len(keys), 0))
+		t.Run("not called", assertEq(atomic.LoadInt32(&called), 0))
+	})
+
+	t.Run("concurrent error", func(t *testing.T) {
+		t.Parallel()
+
+		var slow GetKeys[uint8, string, uint8, int] = func(
+			ctx context.Context,
+			_ uint8,
+			_ *string,
+			_ *uint8,
+		) ([]int, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		var broken GetKeys[uint8, string, uint8, int] = func(
+			_ context.Context,
+			_ uint8,
+			_ *string,
+			_ *uint8,
+		) ([]int, error) {
+			return nil, testErrorInvalidKey
+		}
+		_, e := GetKeysUnion(hash, true, slow, broken, a)(context.Background(), 0, nil, nil)
+		t.Run("root cause", assertEq(errors.Is(e, testErrorInvalidKey), true))
+	})
+
+	t.Run("GetByKeysNew", func(t *testing.T) {
+		t.Parallel()
+
+		var vals []int
+		f := GetByKeysNew(
+			GetKeysIntersect(hash, true, a, b),
+			GetByKey[uint8, string, uint8, int, int](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				key int,
+				val *int,
+				_ *uint8,
+			) (bool, error) {
+				*val = key * 10
+				return true, nil
+			}),
+		)
+		var buf int
+		e := f(context.Background(), 0, nil, nil, &buf, func(val *int, _ *uint8) (bool, error) {
+			vals = append(vals, *val)
+			return false, nil
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("3 items", assertEq(len(vals), 3))
+		t.Run("first", assertEq(vals[0], 50))
+	})
+}
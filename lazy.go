@@ -0,0 +1,90 @@
+package local
+
+import (
+	"context"
+)
+
+// Projection must get only the fields required by a filter from an encoded item.
+type Projection[E, R any] func(encoded *E) (projected R, e error)
+
+// ConsumerLazyNew creates a new IterConsumerFiltered which decodes an item in two phases.
+//
+// The projected item is checked first; the full decoder is used only for kept items.
+//
+// The consumer keeps no state between items;
+// it is safe for concurrent use if decodedConsumer, project, filterProjected and decoder are.
+//
+// # Arguments
+//   - decodedConsumer: Uses decoded items.
+//   - project: Gets a cheap projection from an encoded item.
+//   - filterProjected: Checks if a projected item must be used or not.
+//   - decoder: Gets a decoded item from an encoded item.
+func ConsumerLazyNew[E, R, D, F any](
+	decodedConsumer IterConsumerFiltered[D, F],
+	project Projection[E, R],
+	filterProjected func(projected *R, filter *F) (keep bool),
+	decoder func(encoded *E) (decoded D, e error),
+) IterConsumerFiltered[E, F] {
+	return func(encoded *E, filter *F) (stop bool, e error) {
+		projected, e := project(encoded)
+		if nil != e {
+			return true, e
+		}
+		var keep bool = filterProjected(&projected, filter)
+		if !keep {
+			return false, nil
+		}
+		decoded, e := decoder(encoded)
+		if nil != e {
+			return true, e
+		}
+		return decodedConsumer(&decoded, filter)
+	}
+}
+
+// GetByKeyLazyNew creates a new closure which gets a decoded value in two phases.
+//
+// The returned closure must not be used concurrently(buf is shared);
+// use GetByKeyLazyNewPooled instead.
+//
+// # Arguments
+//   - getEncodedByKey: Gets an encoded value.
+//   - project: Gets a cheap projection from an encoded value.
+//   - filterProjected: Checks if a projected item must be used or not.
+//   - decoder: Gets a decoded value from an encoded value.
+//   - buf: A buffer to save an encoded item.
+func GetByKeyLazyNew[G, B, F, K, E, R, D any](
+	getEncodedByKey GetByKey[G, B, F, K, E],
+	project Projection[E, R],
+	filterProjected func(projected *R, filter *F) (keep bool),
+	decoder func(encoded *E) (decoded D, e error),
+	buf *E,
+) GetByKey[G, B, F, K, D] {
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		key K,
+		val *D,
+		filter *F,
+	) (got bool, e error) {
+		got, e = getEncodedByKey(ctx, con, bucket, key, buf, filter)
+		if nil != e || !got {
+			return false, e
+		}
+		projected, e := project(buf)
+		if nil != e {
+			return false, e
+		}
+		var keep bool = filterProjected(&projected, filter)
+		if !keep {
+			return false, nil
+		}
+		decoded, e := decoder(buf)
+		if nil != e {
+			return false, e
+		}
+		*val = decoded
+		return true, nil
+	}
+}
@@ -0,0 +1,97 @@
+package local
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	t.Parallel()
+
+	project := func(encoded *testIndirectEncoded) (coarse uint16, e error) {
+		return binary.BigEndian.Uint16(encoded.key[8:10]), nil
+	}
+	filterProjected := func(coarse *uint16, f *testIndirectFilter) (keep bool) {
+		return *coarse == f.coarse
+	}
+
+	t.Run("ConsumerLazyNew", func(t *testing.T) {
+		t.Parallel()
+
+		var decoded []testIndirectDecoded
+		var decodeCount int = 0
+		consumer := ConsumerLazyNew(
+			func(d *testIndirectDecoded, f *testIndirectFilter) (stop bool, e error) {
+				decoded = append(decoded, *d)
+				return
+			},
+			project,
+			filterProjected,
+			func(encoded *testIndirectEncoded) (testIndirectDecoded, error) {
+				decodeCount += 1
+				return encoded.decode()
+			},
+		)
+
+		var filter testIndirectFilter = testIndirectFilter{coarse: 0x3776}
+		for _, coarse := range []uint16{0x3776, 0x0634, 0x3776, 0x0599} {
+			var encoded testIndirectEncoded
+			binary.BigEndian.PutUint16(encoded.key[8:10], coarse)
+			_, e := consumer(&encoded, &filter)
+			t.Run("no error", assertNil(e))
+		}
+
+		t.Run("2 items", assertEq(len(decoded), 2))
+		t.Run("2 decodes", assertEq(decodeCount, 2))
+		t.Run("coarse", assertEq(decoded[1].coarse, 0x3776))
+	})
+
+	t.Run("GetByKeyLazyNew", func(t *testing.T) {
+		t.Parallel()
+
+		var buf testIndirectEncoded
+		var decodeCount int = 0
+		var getByKey GetByKey[
+			uint8, Bucket, testIndirectFilter, uint16, testIndirectDecoded,
+		] = GetByKeyLazyNew(
+			GetByKey[uint8, Bucket, testIndirectFilter, uint16, testIndirectEncoded](func(
+				_ context.Context,
+				_con uint8,
+				_b *Bucket,
+				key uint16,
+				val *testIndirectEncoded,
+				_f *testIndirectFilter,
+			) (got bool, e error) {
+				binary.BigEndian.PutUint16(val.key[8:10], key)
+				return 0 != key, nil
+			}),
+			project,
+			filterProjected,
+			func(encoded *testIndirectEncoded) (testIndirectDecoded, error) {
+				decodeCount += 1
+				return encoded.decode()
+			},
+			&buf,
+		)
+
+		var bkt Bucket = BucketNew("")
+		var filter testIndirectFilter = testIndirectFilter{coarse: 0x3776}
+		var decoded testIndirectDecoded
+
+		got, e := getByKey(context.Background(), 0, &bkt, 0x0634, &decoded, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("skipped", assertEq(got, false))
+		t.Run("not decoded", assertEq(decodeCount, 0))
+
+		got, e = getByKey(context.Background(), 0, &bkt, 0, &decoded, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("missing", assertEq(got, false))
+
+		got, e = getByKey(context.Background(), 0, &bkt, 0x3776, &decoded, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("got", assertEq(got, true))
+		t.Run("decoded", assertEq(decodeCount, 1))
+		t.Run("coarse", assertEq(decoded.coarse, 0x3776))
+	})
+}
@@ -2,6 +2,8 @@ package local
 
 // LocalFilterNew creates a new closure which returns only required values.
 //
+// Filtered values are collected for each call; the closure is safe for concurrent use if f is.
+//
 // # Arguments
 //   - f: The closure which checks if a value required or not.
 func LocalFilterNew[V, F any](f func(V, F) (keep bool)) func(all []V, filter F) []V {
@@ -9,6 +9,9 @@ type Unnest[P, U any] func(packed *P) (unnested []U, e error)
 
 // Iter2ConsumerNewUnnested creates a closure which consumes unnested items after filtering.
 //
+// The unnested items of a packed item are local to a call;
+// concurrent calls are safe if the closures are and each call has its own iterator and buf.
+//
 // # Arguments
 //   - iterNext: Checks if an iterator has a next item or not.
 //   - iterGet: Gets a packed item.
@@ -27,8 +30,14 @@ func Iter2ConsumerNewUnnested[I, P, F, U any](
 	consumeUnpacked IterConsumer[U],
 ) func(ctx context.Context, iter I, buf *P, filter *F) error {
 	return func(ctx context.Context, iter I, buf *P, filter *F) error {
+		var cc cancelCheck = cancelCheckNew(ctx)
 		for iterNext(iter) {
-			e := iterGet(iter, buf)
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+
+			e = iterGet(iter, buf)
 			if nil != e {
 				return e
 			}
@@ -59,3 +68,158 @@ func Iter2ConsumerNewUnnested[I, P, F, U any](
 		return iterErr(iter)
 	}
 }
+
+// UnnestEach must pass unnested items to a callback one by one.
+//
+// The callback must not retain the unnested item(buf is reused).
+//
+// # Arguments
+//   - packed: A packed item.
+//   - buf: The buffer to save an unnested item.
+//   - each: Uses an unnested item; returns true to stop.
+type UnnestEach[P, U any] func(
+	packed *P,
+	buf *U,
+	each func(unnested *U) (stop bool, e error),
+) (stop bool, e error)
+
+// UnnestAppend must append unnested items to a reusable buffer.
+type UnnestAppend[P, U any] func(packed *P, buf []U) (unnested []U, e error)
+
+// ToEach creates an UnnestEach which uses an Unnest(allocates a slice per packed item).
+//
+// Unlike UnnestAppend.ToEach, the UnnestEach keeps no slice and is safe for concurrent use if n is.
+func (n Unnest[P, U]) ToEach() UnnestEach[P, U] {
+	return func(packed *P, buf *U, each func(*U) (bool, error)) (stop bool, e error) {
+		unnested, e := n(packed)
+		if nil != e {
+			return true, e
+		}
+		for _, item := range unnested {
+			*buf = item
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		return false, nil
+	}
+}
+
+// ToEach creates an UnnestEach which reuses a slice across packed items.
+//
+// The returned UnnestEach must not be used concurrently.
+func (a UnnestAppend[P, U]) ToEach() UnnestEach[P, U] {
+	var reuse []U
+	return func(packed *P, buf *U, each func(*U) (bool, error)) (stop bool, e error) {
+		reuse, e = a(packed, reuse[:0])
+		if nil != e {
+			return true, e
+		}
+		for ix := range reuse {
+			stop, e = each(&reuse[ix])
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		return false, nil
+	}
+}
+
+// Iter2ConsumerNewUnnestedEach creates a closure like Iter2ConsumerNewUnnested
+// which does not allocate a slice for each packed item.
+//
+// The buffer for an unnested item is created for each call;
+// the closure is safe for concurrent use if its arguments are and buf is not shared.
+//
+// # Arguments
+//   - iterNext: Checks if an iterator has a next item or not.
+//   - iterGet: Gets a packed item.
+//   - iterErr: Gets an error from an iterator.
+//   - unnest: Passes unnested items to a callback.
+//   - filterPacked: Checks if a packed item is required or not.
+//   - filterUnpacked: Checks if an unpacked item is required or not.
+//   - consumeUnpacked: Processes an unpacked item.
+func Iter2ConsumerNewUnnestedEach[I, P, F, U any](
+	iterNext func(iter I) bool,
+	iterGet func(iter I, packed *P) error,
+	iterErr func(iter I) error,
+	unnest UnnestEach[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	filterUnpacked func(unpacked *U, filter *F) (keep bool),
+	consumeUnpacked IterConsumer[U],
+) func(ctx context.Context, iter I, buf *P, filter *F) error {
+	return func(ctx context.Context, iter I, buf *P, filter *F) error {
+		var child U
+		each := func(unpacked *U) (stop bool, e error) {
+			var keep bool = filterUnpacked(unpacked, filter)
+			if !keep {
+				return false, nil
+			}
+			return consumeUnpacked(unpacked)
+		}
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for iterNext(iter) {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+
+			e = iterGet(iter, buf)
+			if nil != e {
+				return e
+			}
+
+			var keepPacked bool = filterPacked(buf, filter)
+			if !keepPacked {
+				continue
+			}
+
+			stop, e := unnest(buf, &child, each)
+			if nil != e {
+				return e
+			}
+			if stop {
+				return nil
+			}
+		}
+		return iterErr(iter)
+	}
+}
+
+// ToUnnest creates an Unnest which collects items passed by an UnnestEach.
+//
+// The collected items are copies so that the Unnest is safe for concurrent use if n is.
+func (n UnnestEach[P, U]) ToUnnest() Unnest[P, U] {
+	return func(packed *P) (unnested []U, e error) {
+		var buf U
+		_, e = n(packed, &buf, func(item *U) (stop bool, e error) {
+			unnested = append(unnested, *item)
+			return false, nil
+		})
+		return
+	}
+}
+
+// ToUnpack creates an Unpack which uses an Unnest.
+//
+// The Unpack adds no state to n.
+func (n Unnest[P, U]) ToUnpack() Unpack[P, U] {
+	return func(packed P) (unpacked []U, e error) { return n(&packed) }
+}
+
+// UnnestFiltered must pass unnested items which may be required by a filter to a callback.
+//
+// Unlike UnnestEach, the filter can be used before unnested items are materialized.
+//
+// # Arguments
+//   - packed: A packed item.
+//   - filter: A filter which may be used to skip unnested items.
+//   - buf: The buffer to save an unnested item.
+//   - each: Uses an unnested item; returns true to stop.
+type UnnestFiltered[P, U, F any] func(
+	packed *P,
+	filter *F,
+	buf *U,
+	each func(unnested *U) (stop bool, e error),
+) (stop bool, e error)
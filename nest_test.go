@@ -10,31 +10,49 @@ type testNestPackedItem struct {
 	key uint8
 }
 
-func (p *testNestPackedItem) Unpack() (unpacked []testNestUnpackedItem) {
-	for _, item := range p.val {
-		var u uint64 = item
-		var hi uint32 = uint32(u >> 32)
-		var lo uint32 = uint32(u & 0xffffffff)
+func (p *testNestPackedItem) unpackAt(ix int) testNestUnpackedItem {
+	var u uint64 = p.val[ix]
+	var hi uint32 = uint32(u >> 32)
+	var lo uint32 = uint32(u & 0xffffffff)
 
-		var serial int32 = int32(hi)
+	var serial int32 = int32(hi)
 
-		var lh uint16 = uint16(lo >> 16)
-		var ll uint16 = uint16(lo & 0xffff)
+	var lh uint16 = uint16(lo >> 16)
+	var ll uint16 = uint16(lo & 0xffff)
 
-		var status uint16 = lh
-		var full uint16 = (uint16(p.key) << 8) | (ll >> 8)
-		var valid uint8 = uint8(ll & 0xff)
+	var status uint16 = lh
+	var full uint16 = (uint16(p.key) << 8) | (ll >> 8)
+	var valid uint8 = uint8(ll & 0xff)
 
-		unpacked = append(unpacked, testNestUnpackedItem{
-			serial,
-			status,
-			full,
-			valid,
-		})
+	return testNestUnpackedItem{
+		serial,
+		status,
+		full,
+		valid,
+	}
+}
+
+func (p *testNestPackedItem) Unpack() (unpacked []testNestUnpackedItem) {
+	for ix := range p.val {
+		unpacked = append(unpacked, p.unpackAt(ix))
 	}
 	return
 }
 
+func (p *testNestPackedItem) UnpackEach(
+	buf *testNestUnpackedItem,
+	each func(*testNestUnpackedItem) (bool, error),
+) (stop bool, e error) {
+	for ix := range p.val {
+		*buf = p.unpackAt(ix)
+		stop, e = each(buf)
+		if nil != e || stop {
+			return true, e
+		}
+	}
+	return false, nil
+}
+
 type testNestUnpackedItem struct {
 	serial int32
 	status uint16
@@ -349,4 +367,177 @@ func TestNest(t *testing.T) {
 			t.Run("9 unpacked items", assertEq(len(buf), 9))
 		})
 	})
+
+	t.Run("Iter2ConsumerNewUnnestedEach", func(t *testing.T) {
+		t.Parallel()
+
+		var each UnnestEach[testNestPackedItem, testNestUnpackedItem] = func(
+			packed *testNestPackedItem,
+			buf *testNestUnpackedItem,
+			f func(*testNestUnpackedItem) (bool, error),
+		) (bool, error) {
+			return packed.UnpackEach(buf, f)
+		}
+
+		iterNext := func(iter *uint8) (hasNext bool) { return *iter < 100 }
+		iterGet := func(iter *uint8, p *testNestPackedItem) error {
+			p.key = *iter
+			p.val = [8]uint64{
+				0x0123456789abcdef,
+				0x1123456789abcdef,
+				0x2123456789abcdef,
+				0x3123456789abcdef,
+				0x4123456789abcdef,
+				0x5123456789abcdef,
+				0x6123456789abcdef,
+				0x7123456789abcdef,
+			}
+			*iter += 1
+			return nil
+		}
+		iterErr := func(iter *uint8) error { return nil }
+		filterPacked := func(packed *testNestPackedItem, f *testNestFilter) (keep bool) {
+			return 0 != packed.key
+		}
+		filterUnpacked := func(unpacked *testNestUnpackedItem, f *testNestFilter) (keep bool) {
+			return f.serial <= unpacked.serial
+		}
+
+		t.Run("filtered", func(t *testing.T) {
+			t.Parallel()
+
+			var cnt int = 0
+			f := Iter2ConsumerNewUnnestedEach(
+				iterNext,
+				iterGet,
+				iterErr,
+				each,
+				filterPacked,
+				filterUnpacked,
+				func(value *testNestUnpackedItem) (stop bool, e error) {
+					cnt += 1
+					return false, nil
+				},
+			)
+
+			var iter uint8 = 0
+			var packedBuf testNestPackedItem
+			var filter testNestFilter = testNestFilter{serial: 0x71234567}
+			e := f(context.Background(), &iter, &packedBuf, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("99 unpacked items", assertEq(cnt, 99))
+		})
+
+		t.Run("stop", func(t *testing.T) {
+			t.Parallel()
+
+			var buf []testNestUnpackedItem
+			f := Iter2ConsumerNewUnnestedEach(
+				iterNext,
+				iterGet,
+				iterErr,
+				Unnest[testNestPackedItem, testNestUnpackedItem](func(
+					packed *testNestPackedItem,
+				) ([]testNestUnpackedItem, error) {
+					return packed.Unpack(), nil
+				}).ToEach(),
+				filterPacked,
+				filterUnpacked,
+				func(value *testNestUnpackedItem) (stop bool, e error) {
+					buf = append(buf, *value)
+					return 9 == len(buf), nil
+				},
+			)
+
+			var iter uint8 = 0
+			var packedBuf testNestPackedItem
+			var filter testNestFilter
+			e := f(context.Background(), &iter, &packedBuf, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("9 unpacked items", assertEq(len(buf), 9))
+			t.Run("2 packed items", assertEq(iter, 3))
+		})
+
+		t.Run("append", func(t *testing.T) {
+			t.Parallel()
+
+			var cnt int = 0
+			f := Iter2ConsumerNewUnnestedEach(
+				iterNext,
+				iterGet,
+				iterErr,
+				UnnestAppend[testNestPackedItem, testNestUnpackedItem](func(
+					packed *testNestPackedItem,
+					buf []testNestUnpackedItem,
+				) ([]testNestUnpackedItem, error) {
+					for ix := range packed.val {
+						buf = append(buf, packed.unpackAt(ix))
+					}
+					return buf, nil
+				}).ToEach(),
+				filterPacked,
+				filterUnpacked,
+				func(value *testNestUnpackedItem) (stop bool, e error) {
+					cnt += 1
+					return false, nil
+				},
+			)
+
+			var iter uint8 = 0
+			var packedBuf testNestPackedItem
+			var filter testNestFilter
+			e := f(context.Background(), &iter, &packedBuf, &filter)
+			t.Run("no error", assertNil(e))
+			t.Run("792 unpacked items", assertEq(cnt, 792))
+		})
+	})
+}
+
+func TestNestAllocs(t *testing.T) {
+	var each UnnestEach[testNestPackedItem, testNestUnpackedItem] = func(
+		packed *testNestPackedItem,
+		buf *testNestUnpackedItem,
+		f func(*testNestUnpackedItem) (bool, error),
+	) (bool, error) {
+		return packed.UnpackEach(buf, f)
+	}
+
+	iterNext := func(iter *uint8) (hasNext bool) { return *iter < 100 }
+	iterGet := func(iter *uint8, p *testNestPackedItem) error {
+		p.key = *iter
+		p.val[0] = uint64(*iter)
+		*iter += 1
+		return nil
+	}
+	iterErr := func(iter *uint8) error { return nil }
+	filterPacked := func(packed *testNestPackedItem, f *testNestFilter) (keep bool) {
+		return true
+	}
+	filterUnpacked := func(unpacked *testNestUnpackedItem, f *testNestFilter) (keep bool) {
+		return true
+	}
+
+	var cnt int = 0
+	f := Iter2ConsumerNewUnnestedEach(
+		iterNext,
+		iterGet,
+		iterErr,
+		each,
+		filterPacked,
+		filterUnpacked,
+		func(value *testNestUnpackedItem) (stop bool, e error) {
+			cnt += 1
+			return false, nil
+		},
+	)
+
+	var iter uint8
+	var packedBuf testNestPackedItem
+	var filter testNestFilter
+	var ctx context.Context = context.Background()
+	allocs := testing.AllocsPerRun(10, func() {
+		iter = 0
+		_ = f(ctx, &iter, &packedBuf, &filter)
+	})
+	t.Run("constant allocations", assertEq(allocs <= 4, true))
 }
@@ -0,0 +1,262 @@
+package local
+
+import (
+	"context"
+	"sync"
+)
+
+// ParallelOrder decides the order of decoded items.
+type ParallelOrder uint8
+
+const (
+	// ParallelOrderInput keeps the input order.
+	ParallelOrderInput ParallelOrder = iota
+
+	// ParallelOrderArrival uses the order in which items are decoded.
+	ParallelOrderArrival
+)
+
+// ParallelConfig contains the number of workers and the memory bound.
+type ParallelConfig struct {
+	workers int
+	window  int
+	order   ParallelOrder
+}
+
+// ParallelConfigNew creates a ParallelConfig.
+//
+// The window(max number of items read but not yet emitted) is 2 * workers.
+//
+// # Arguments
+//   - workers: The number of decode workers(at least 1).
+//   - order: The order of decoded items.
+func ParallelConfigNew(workers int, order ParallelOrder) ParallelConfig {
+	if workers < 1 {
+		workers = 1
+	}
+	return ParallelConfig{
+		workers: workers,
+		window:  2 * workers,
+		order:   order,
+	}
+}
+
+// WithWindow creates a new ParallelConfig which uses the window.
+//
+// # Arguments
+//   - window: Max number of items read but not yet emitted(at least workers).
+func (c ParallelConfig) WithWindow(window int) ParallelConfig {
+	if window < c.workers {
+		window = c.workers
+	}
+	c.window = window
+	return c
+}
+
+type parallelJob[E any] struct {
+	index   int
+	encoded E
+}
+
+type parallelResult[D any] struct {
+	index   int
+	decoded D
+	err     error
+}
+
+// parallelDecode decodes items using workers.
+//
+// next is called from a single goroutine and gets an item into a fresh buffer.
+// decode gets a context which is cancelled when the decoding is aborted.
+func parallelDecode[E, D any](
+	ctx context.Context,
+	cfg ParallelConfig,
+	next func(buf *E) (got bool, e error),
+	decode func(ctx context.Context, encoded *E) (decoded D, e error),
+	emit func(decoded D) error,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var jobs chan parallelJob[E] = make(chan parallelJob[E], cfg.workers)
+	var results chan parallelResult[D] = make(chan parallelResult[D], cfg.window)
+	var slots chan struct{} = make(chan struct{}, cfg.window)
+
+	var wg sync.WaitGroup
+	var nextErr error
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for ix := 0; ; ix++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			var buf E
+			got, e := next(&buf)
+			if nil != e {
+				nextErr = e
+				return
+			}
+			if !got {
+				return
+			}
+			select {
+			case jobs <- parallelJob[E]{index: ix, encoded: buf}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Add(cfg.workers)
+	for i := 0; i < cfg.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				decoded, e := decode(ctx, &job.encoded)
+				select {
+				case results <- parallelResult[D]{job.index, decoded, e}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var pending map[int]parallelResult[D] = make(map[int]parallelResult[D])
+	var nextIx int = 0
+
+	consume := func(r parallelResult[D]) error {
+		<-slots
+		if nil != r.err {
+			return r.err
+		}
+		return emit(r.decoded)
+	}
+
+	var err error
+	for r := range results {
+		if nil != err {
+			continue
+		}
+		if ParallelOrderArrival == cfg.order {
+			err = consume(r)
+		} else {
+			pending[r.index] = r
+			for nil == err {
+				found, ok := pending[nextIx]
+				if !ok {
+					break
+				}
+				delete(pending, nextIx)
+				nextIx += 1
+				err = consume(found)
+			}
+		}
+		if nil != err {
+			cancel()
+		}
+	}
+	if nil != err {
+		return err
+	}
+	if nil != nextErr {
+		return nextErr
+	}
+	return ctx.Err()
+}
+
+// NewAllParallel creates a closure which gets decoded items using workers.
+//
+// d is called by the workers at once so that it must be safe for concurrent use;
+// then the closure is also safe for concurrent use if all is.
+//
+// # Arguments
+//   - all: Gets encoded items.
+//   - cfg: The number of workers, the memory bound and the order.
+func (d Decode[E, D]) NewAllParallel(
+	all func(context.Context, Bucket) (encoded []E, e error),
+	cfg ParallelConfig,
+) func(context.Context, Bucket) (decoded []D, e error) {
+	return func(ctx context.Context, bkt Bucket) (decoded []D, e error) {
+		encoded, e := all(ctx, bkt)
+		if nil != e {
+			return nil, e
+		}
+		var ix int = 0
+		e = parallelDecode(
+			ctx,
+			cfg,
+			func(buf *E) (got bool, e error) {
+				if len(encoded) <= ix {
+					return false, nil
+				}
+				*buf = encoded[ix]
+				ix += 1
+				return true, nil
+			},
+			func(_ context.Context, encodedItem *E) (D, error) { return d(*encodedItem) },
+			func(decodedItem D) error {
+				decoded = append(decoded, decodedItem)
+				return nil
+			},
+		)
+		if nil != e {
+			return nil, e
+		}
+		return
+	}
+}
+
+// Iter2UnpackedNewParallel creates a new closure which gets unpacked items using workers.
+//
+// The iterator is used by a single goroutine.
+// Each packed item is read into a fresh(zero) buffer
+// so that getPacked must not share memory between items.
+// packed2unpacked is called by the workers at once and must be safe for concurrent use.
+// The closure is safe for concurrent use if the iterator functions are(each call has its own iter).
+//
+// # Arguments
+//   - packed2unpacked: Gets an unpacked item from a packed item.
+//   - hasNext: Checks if an iterator has a next item.
+//   - getPacked: Gets a packed item from an iterator.
+//   - getError: Gets an error if exists.
+//   - cfg: The number of workers, the memory bound and the order.
+func Iter2UnpackedNewParallel[I, P, U any](
+	packed2unpacked func(packed *P) (unpacked U, e error),
+	hasNext func(iter I) bool,
+	getPacked func(iter I, buf *P) error,
+	getError func(iter I) error,
+	cfg ParallelConfig,
+) func(ctx context.Context, iter I) (unpacked []U, e error) {
+	return func(ctx context.Context, iter I) (unpacked []U, e error) {
+		e = parallelDecode(
+			ctx,
+			cfg,
+			func(buf *P) (got bool, e error) {
+				if !hasNext(iter) {
+					return false, getError(iter)
+				}
+				return true, getPacked(iter, buf)
+			},
+			func(_ context.Context, packed *P) (U, error) { return packed2unpacked(packed) },
+			func(unpackedItem U) error {
+				unpacked = append(unpacked, unpackedItem)
+				return nil
+			},
+		)
+		if nil != e {
+			return nil, e
+		}
+		return
+	}
+}
@@ -0,0 +1,212 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	t.Parallel()
+
+	var decode Decode[uint32, uint64] = func(encoded uint32) (uint64, error) {
+		time.Sleep(time.Duration(encoded%7) * time.Microsecond)
+		if 0xdead == encoded {
+			return 0, testErrorInvalidVal
+		}
+		return uint64(encoded) << 1, nil
+	}
+
+	getEncodedNew := func(size int) func(context.Context, Bucket) ([]uint32, error) {
+		return func(_ context.Context, _b Bucket) ([]uint32, error) {
+			var encoded []uint32
+			for i := 0; i < size; i++ {
+				encoded = append(encoded, uint32(i))
+			}
+			return encoded, nil
+		}
+	}
+
+	t.Run("Decode", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("NewAllParallel", func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("empty", func(t *testing.T) {
+				t.Parallel()
+
+				getDecoded := decode.NewAllParallel(
+					getEncodedNew(0),
+					ParallelConfigNew(4, ParallelOrderInput),
+				)
+				decoded, e := getDecoded(context.Background(), BucketNew(""))
+				t.Run("no error", assertNil(e))
+				t.Run("no items", assertEq(len(decoded), 0))
+			})
+
+			t.Run("input order", func(t *testing.T) {
+				t.Parallel()
+
+				getDecoded := decode.NewAllParallel(
+					getEncodedNew(1000),
+					ParallelConfigNew(8, ParallelOrderInput),
+				)
+				decoded, e := getDecoded(context.Background(), BucketNew(""))
+				t.Run("no error", assertNil(e))
+				t.Run("1000 items", assertEq(len(decoded), 1000))
+
+				var ordered bool = true
+				for ix, item := range decoded {
+					ordered = ordered && uint64(ix)<<1 == item
+				}
+				t.Run("ordered", assertEq(ordered, true))
+			})
+
+			t.Run("arrival order", func(t *testing.T) {
+				t.Parallel()
+
+				getDecoded := decode.NewAllParallel(
+					getEncodedNew(1000),
+					ParallelConfigNew(8, ParallelOrderArrival).WithWindow(64),
+				)
+				decoded, e := getDecoded(context.Background(), BucketNew(""))
+				t.Run("no error", assertNil(e))
+				t.Run("1000 items", assertEq(len(decoded), 1000))
+
+				sort.Slice(decoded, func(i, j int) bool { return decoded[i] < decoded[j] })
+				var complete bool = true
+				for ix, item := range decoded {
+					complete = complete && uint64(ix)<<1 == item
+				}
+				t.Run("all items", assertEq(complete, true))
+			})
+
+			t.Run("invalid item", func(t *testing.T) {
+				t.Parallel()
+
+				getDecoded := decode.NewAllParallel(
+					getEncodedNew(0x10000),
+					ParallelConfigNew(4, ParallelOrderInput),
+				)
+				_, e := getDecoded(context.Background(), BucketNew(""))
+				t.Run("error", assertEq(errors.Is(e, testErrorInvalidVal), true))
+			})
+		})
+	})
+
+	t.Run("Iter2UnpackedNewParallel", func(t *testing.T) {
+		t.Parallel()
+
+		type iter struct {
+			next uint32
+			size uint32
+			err  error
+		}
+
+		hasNext := func(it *iter) bool { return it.next < it.size }
+		getPacked := func(it *iter, buf *uint32) error {
+			*buf = it.next
+			it.next += 1
+			return nil
+		}
+		getError := func(it *iter) error { return it.err }
+
+		packed2unpacked := func(packed *uint32) (uint64, error) { return decode(*packed) }
+
+		t.Run("input order", func(t *testing.T) {
+			t.Parallel()
+
+			var it iter = iter{size: 500}
+			f := Iter2UnpackedNewParallel(
+				packed2unpacked,
+				hasNext,
+				getPacked,
+				getError,
+				ParallelConfigNew(4, ParallelOrderInput),
+			)
+			unpacked, e := f(context.Background(), &it)
+			t.Run("no error", assertNil(e))
+			t.Run("500 items", assertEq(len(unpacked), 500))
+			t.Run("last item", assertEq(unpacked[499], 998))
+		})
+
+		t.Run("iterator error", func(t *testing.T) {
+			t.Parallel()
+
+			var it iter = iter{size: 3, err: testErrorInvalidKey}
+			f := Iter2UnpackedNewParallel(
+				packed2unpacked,
+				hasNext,
+				getPacked,
+				getError,
+				ParallelConfigNew(2, ParallelOrderInput),
+			)
+			_, e := f(context.Background(), &it)
+			t.Run("error", assertEq(errors.Is(e, testErrorInvalidKey), true))
+		})
+
+		t.Run("cancelled", func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			var it iter = iter{size: 1000000}
+			f := Iter2UnpackedNewParallel(
+				packed2unpacked,
+				hasNext,
+				getPacked,
+				getError,
+				ParallelConfigNew(2, ParallelOrderInput),
+			)
+			_, e := f(ctx, &it)
+			t.Run("error", assertEq(errors.Is(e, context.Canceled), true))
+			t.Run("not scanned", assertEq(it.next < it.size, true))
+		})
+	})
+
+	t.Run("parallelDecode", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("window", func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var outstanding int = 0
+			var peak int = 0
+			var ix uint32 = 0
+
+			e := parallelDecode(
+				context.Background(),
+				ParallelConfigNew(4, ParallelOrderInput).WithWindow(6),
+				func(buf *uint32) (got bool, e error) {
+					if 200 <= ix {
+						return false, nil
+					}
+					mu.Lock()
+					outstanding += 1
+					if peak < outstanding {
+						peak = outstanding
+					}
+					mu.Unlock()
+					*buf = ix
+					ix += 1
+					return true, nil
+				},
+				func(_ context.Context, encoded *uint32) (uint64, error) { return decode(*encoded) },
+				func(decoded uint64) error {
+					mu.Lock()
+					outstanding -= 1
+					mu.Unlock()
+					return nil
+				},
+			)
+			t.Run("no error", assertNil(e))
+			t.Run("bounded", assertEq(peak <= 6, true))
+		})
+	})
+}
@@ -0,0 +1,188 @@
+package local
+
+import (
+	"context"
+)
+
+// ChildFilter must check if an unnested item must be used or not.
+//
+// # Arguments
+//   - parent: The packed item which contains the child.
+//   - child: An unnested item.
+//   - ordinal: The index of the child in the parent.
+//   - filter: A filter.
+type ChildFilter[P, U, F any] func(parent *P, child *U, ordinal int, filter *F) (keep bool)
+
+// ChildConsumer may consume an unnested item.
+//
+// The parent may be reused after the consumer returns; it must be copied if it is retained.
+//
+// # Arguments
+//   - parent: The packed item which contains the child.
+//   - child: An unnested item.
+//   - ordinal: The index of the child in the parent.
+type ChildConsumer[P, U any] func(parent *P, child *U, ordinal int) (stop bool, e error)
+
+func consumeChildren[P, U, F any](
+	parent *P,
+	children []U,
+	filterChild ChildFilter[P, U, F],
+	consumeChild ChildConsumer[P, U],
+	filter *F,
+) (stop bool, e error) {
+	for ix := range children {
+		var child *U = &children[ix]
+		var keep bool = filterChild(parent, child, ix, filter)
+		if !keep {
+			continue
+		}
+		stop, e = consumeChild(parent, child, ix)
+		if nil != e || stop {
+			return true, e
+		}
+	}
+	return false, nil
+}
+
+// Iter2ConsumerNewUnnestedWithParent creates a closure like Iter2ConsumerNewUnnested
+// whose child filter and consumer can use the parent.
+//
+// The parent is kept only in buf(the caller's);
+// the closure is safe for concurrent use if its closures are and buf is not shared.
+//
+// # Arguments
+//   - iterNext: Checks if an iterator has a next item or not.
+//   - iterGet: Gets a packed item.
+//   - iterErr: Gets an error from an iterator.
+//   - unnest: Gets unnested items from a packed value.
+//   - filterPacked: Checks if a packed item is required or not.
+//   - filterChild: Checks if an unnested item is required or not.
+//   - consumeChild: Processes an unnested item.
+func Iter2ConsumerNewUnnestedWithParent[I, P, F, U any](
+	iterNext func(iter I) bool,
+	iterGet func(iter I, packed *P) error,
+	iterErr func(iter I) error,
+	unnest Unnest[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	filterChild ChildFilter[P, U, F],
+	consumeChild ChildConsumer[P, U],
+) func(ctx context.Context, iter I, buf *P, filter *F) error {
+	return func(ctx context.Context, iter I, buf *P, filter *F) error {
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for iterNext(iter) {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+
+			e = iterGet(iter, buf)
+			if nil != e {
+				return e
+			}
+
+			var keepPacked bool = filterPacked(buf, filter)
+			if !keepPacked {
+				continue
+			}
+
+			children, e := unnest(buf)
+			if nil != e {
+				return e
+			}
+
+			stop, e := consumeChildren(buf, children, filterChild, consumeChild, filter)
+			if nil != e {
+				return e
+			}
+			if stop {
+				return nil
+			}
+		}
+		return iterErr(iter)
+	}
+}
+
+// GetByKeysNewUnnestedWithParent creates a closure like GetByKeysNewUnnested
+// whose child filter and consumer can use the parent.
+//
+// Nothing is kept between calls; with a buf for each goroutine
+// the closure is safe for concurrent use if getByKey, unnest and the child closures are.
+//
+// # Arguments
+//   - getByKey: Gets a packed item by a key.
+//   - unnest: Gets unnested items from a packed item.
+//   - filterPacked: Checks if a packed item must be used or not.
+//   - filterChild: Check if an unnested item must be used or not.
+//   - consumeChild: Uses an unnested item.
+func GetByKeysNewUnnestedWithParent[G, K, P, F, U any](
+	getByKey func(ctx context.Context, con G, key K, packed *P) (got bool, e error),
+	unnest Unnest[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	filterChild ChildFilter[P, U, F],
+	consumeChild ChildConsumer[P, U],
+) func(ctx context.Context, keys []K, get G, buf *P, filter *F) error {
+	return func(ctx context.Context, keys []K, con G, buf *P, filter *F) error {
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for _, key := range keys {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+
+			got, e := getByKey(ctx, con, key, buf)
+			if nil != e {
+				return e
+			}
+			if !got {
+				continue
+			}
+
+			var keepPacked bool = filterPacked(buf, filter)
+			if !keepPacked {
+				continue
+			}
+
+			children, e := unnest(buf)
+			if nil != e {
+				return e
+			}
+
+			stop, e := consumeChildren(buf, children, filterChild, consumeChild, filter)
+			if nil != e {
+				return e
+			}
+			if stop {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// ConsumerUnnestedWithParentNew creates a new IterConsumerFiltered which consumes packed items.
+//
+// The consumer is safe for concurrent use if unnest, filterPacked and the child closures are.
+//
+// # Arguments
+//   - unnest: Gets unnested items from a packed item.
+//   - filterPacked: Checks if a packed item must be used or not.
+//   - filterChild: Checks if an unnested item must be used or not.
+//   - consumeChild: Uses an unnested item.
+func ConsumerUnnestedWithParentNew[P, U, F any](
+	unnest Unnest[P, U],
+	filterPacked func(packed *P, filter *F) (keep bool),
+	filterChild ChildFilter[P, U, F],
+	consumeChild ChildConsumer[P, U],
+) IterConsumerFiltered[P, F] {
+	return func(packed *P, filter *F) (stop bool, e error) {
+		var keep bool = filterPacked(packed, filter)
+		if !keep {
+			return false, nil
+		}
+		children, e := unnest(packed)
+		if nil != e {
+			return true, e
+		}
+		return consumeChildren(packed, children, filterChild, consumeChild, filter)
+	}
+}
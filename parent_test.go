@@ -0,0 +1,162 @@
+package local
+
+import (
+	"context"
+	"testing"
+)
+
+type testParentMinute struct {
+	minute int32
+	values []int64
+}
+
+type testParentRow struct {
+	minute int32
+	second int
+	value  int64
+}
+
+type testParentFilter struct {
+	minute int32
+	second int
+}
+
+func TestParent(t *testing.T) {
+	t.Parallel()
+
+	var packed []testParentMinute = []testParentMinute{
+		{minute: 1, values: []int64{10, 11, 12}},
+		{minute: 2, values: []int64{20, 21, 22}},
+		{minute: 3, values: []int64{30, 31, 32}},
+	}
+
+	var unnest Unnest[testParentMinute, int64] = func(p *testParentMinute) ([]int64, error) {
+		return p.values, nil
+	}
+
+	var filterPacked func(*testParentMinute, *testParentFilter) bool = func(
+		p *testParentMinute,
+		f *testParentFilter,
+	) bool {
+		return f.minute <= p.minute
+	}
+
+	// (minute, second) >= (f.minute, f.second)
+	var filterChild ChildFilter[testParentMinute, int64, testParentFilter] = func(
+		p *testParentMinute,
+		_ *int64,
+		ordinal int,
+		f *testParentFilter,
+	) bool {
+		return f.minute < p.minute || f.second <= ordinal
+	}
+
+	t.Run("Iter2ConsumerNewUnnestedWithParent", func(t *testing.T) {
+		t.Parallel()
+
+		var rows []testParentRow
+		var consumer ChildConsumer[testParentMinute, int64] = func(
+			p *testParentMinute,
+			child *int64,
+			ordinal int,
+		) (stop bool, e error) {
+			rows = append(rows, testParentRow{p.minute, ordinal, *child})
+			return 3 == len(rows), nil
+		}
+
+		var f func(
+			ctx context.Context,
+			iter *int,
+			buf *testParentMinute,
+			filter *testParentFilter,
+		) error = Iter2ConsumerNewUnnestedWithParent(
+			func(iter *int) bool { return *iter < len(packed) },
+			func(iter *int, p *testParentMinute) error {
+				*p = packed[*iter]
+				*iter += 1
+				return nil
+			},
+			func(_ *int) error { return nil },
+			unnest,
+			filterPacked,
+			filterChild,
+			consumer,
+		)
+
+		var iter int = 0
+		var buf testParentMinute
+		var filter testParentFilter = testParentFilter{minute: 2, second: 2}
+		e := f(context.Background(), &iter, &buf, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("3 rows", assertEq(len(rows), 3))
+		t.Run("first", assertEq(rows[0], testParentRow{2, 2, 22}))
+		t.Run("second", assertEq(rows[1], testParentRow{3, 0, 30}))
+		t.Run("third", assertEq(rows[2], testParentRow{3, 1, 31}))
+	})
+
+	t.Run("GetByKeysNewUnnestedWithParent", func(t *testing.T) {
+		t.Parallel()
+
+		var rows []testParentRow
+		var f func(
+			ctx context.Context,
+			keys []int,
+			con []testParentMinute,
+			buf *testParentMinute,
+			filter *testParentFilter,
+		) error = GetByKeysNewUnnestedWithParent(
+			func(
+				_ context.Context,
+				con []testParentMinute,
+				key int,
+				p *testParentMinute,
+			) (got bool, e error) {
+				if len(con) <= key {
+					return false, nil
+				}
+				*p = con[key]
+				return true, nil
+			},
+			unnest,
+			filterPacked,
+			filterChild,
+			func(p *testParentMinute, child *int64, ordinal int) (stop bool, e error) {
+				rows = append(rows, testParentRow{p.minute, ordinal, *child})
+				return false, nil
+			},
+		)
+
+		var buf testParentMinute
+		var filter testParentFilter = testParentFilter{minute: 1, second: 1}
+		e := f(context.Background(), []int{7, 0, 1}, packed, &buf, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("5 rows", assertEq(len(rows), 5))
+		t.Run("first", assertEq(rows[0], testParentRow{1, 1, 11}))
+		t.Run("last", assertEq(rows[4], testParentRow{2, 2, 22}))
+	})
+
+	t.Run("ConsumerUnnestedWithParentNew", func(t *testing.T) {
+		t.Parallel()
+
+		var rows []testParentRow
+		var consumer IterConsumerFiltered[
+			testParentMinute,
+			testParentFilter,
+		] = ConsumerUnnestedWithParentNew(
+			unnest,
+			filterPacked,
+			filterChild,
+			func(p *testParentMinute, child *int64, ordinal int) (stop bool, e error) {
+				rows = append(rows, testParentRow{p.minute, ordinal, *child})
+				return true, nil
+			},
+		)
+
+		var filter testParentFilter = testParentFilter{minute: 3, second: 1}
+		stop, e := consumer(&packed[2], &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("stop", assertEq(stop, true))
+		t.Run("1 row", assertEq(len(rows), 1))
+		t.Run("first", assertEq(rows[0], testParentRow{3, 1, 31}))
+	})
+}
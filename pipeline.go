@@ -0,0 +1,268 @@
+package local
+
+import (
+	"context"
+)
+
+// Pipeline contains stages which get items(T) from source items(S).
+//
+// Stages which change the item type are functions(PipeDecode, PipeUnnest, PipeMap);
+// other stages are methods.
+type Pipeline[S, T, F any] struct {
+	stage func(next IterConsumerFiltered[T, F]) IterConsumerFiltered[S, F]
+}
+
+// PipelineNew creates a Pipeline which has no stage.
+func PipelineNew[S, F any]() Pipeline[S, S, F] {
+	return Pipeline[S, S, F]{
+		stage: func(next IterConsumerFiltered[S, F]) IterConsumerFiltered[S, F] { return next },
+	}
+}
+
+func pipeNew[S, A, B, F any](
+	p Pipeline[S, A, F],
+	stage func(next IterConsumerFiltered[B, F]) IterConsumerFiltered[A, F],
+) Pipeline[S, B, F] {
+	return Pipeline[S, B, F]{
+		stage: func(next IterConsumerFiltered[B, F]) IterConsumerFiltered[S, F] {
+			return p.stage(stage(next))
+		},
+	}
+}
+
+// Filter creates a new Pipeline which skips items.
+//
+// # Arguments
+//   - keep: Checks if an item must be used or not.
+func (p Pipeline[S, T, F]) Filter(keep func(item *T, filter *F) bool) Pipeline[S, T, F] {
+	return pipeNew(p, func(next IterConsumerFiltered[T, F]) IterConsumerFiltered[T, F] {
+		return func(item *T, filter *F) (stop bool, e error) {
+			if !keep(item, filter) {
+				return false, nil
+			}
+			return next(item, filter)
+		}
+	})
+}
+
+// Sink creates a consumer which uses source items.
+//
+// The returned consumer is safe for concurrent use if all stages are;
+// a consumer which has a PipeUnnestFiltered stage reuses its buffer and is not.
+//
+// # Arguments
+//   - consumer: Uses items got by the stages.
+func (p Pipeline[S, T, F]) Sink(consumer IterConsumerFiltered[T, F]) IterConsumerFiltered[S, F] {
+	return p.stage(consumer)
+}
+
+// PipeDecode creates a new Pipeline which decodes items.
+//
+// # Arguments
+//   - decode: Gets a decoded item from an encoded item.
+func PipeDecode[S, E, D, F any](
+	p Pipeline[S, E, F],
+	decode func(encoded *E) (decoded D, e error),
+) Pipeline[S, D, F] {
+	return pipeNew(p, func(next IterConsumerFiltered[D, F]) IterConsumerFiltered[E, F] {
+		return func(encoded *E, filter *F) (stop bool, e error) {
+			decoded, e := decode(encoded)
+			if nil != e {
+				return true, e
+			}
+			return next(&decoded, filter)
+		}
+	})
+}
+
+// PipeMap creates a new Pipeline which converts items using a filter.
+//
+// # Arguments
+//   - mapper: Gets a new item from an item.
+func PipeMap[S, A, B, F any](
+	p Pipeline[S, A, F],
+	mapper func(item *A, filter *F) (mapped B, e error),
+) Pipeline[S, B, F] {
+	return pipeNew(p, func(next IterConsumerFiltered[B, F]) IterConsumerFiltered[A, F] {
+		return func(item *A, filter *F) (stop bool, e error) {
+			mapped, e := mapper(item, filter)
+			if nil != e {
+				return true, e
+			}
+			return next(&mapped, filter)
+		}
+	})
+}
+
+// PipeUnnest creates a new Pipeline which uses unnested items.
+//
+// # Arguments
+//   - unnest: Gets unnested items from a packed item.
+func PipeUnnest[S, P, U, F any](
+	p Pipeline[S, P, F],
+	unnest Unnest[P, U],
+) Pipeline[S, U, F] {
+	return pipeNew(p, func(next IterConsumerFiltered[U, F]) IterConsumerFiltered[P, F] {
+		return func(packed *P, filter *F) (stop bool, e error) {
+			unnested, e := unnest(packed)
+			if nil != e {
+				return true, e
+			}
+			for _, item := range unnested {
+				var u U = item
+				stop, e := next(&u, filter)
+				if nil != e {
+					return true, e
+				}
+				if stop {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	})
+}
+
+// PipeUnpack creates a new Pipeline which uses unpacked items.
+//
+// # Arguments
+//   - unpack: Gets unpacked items from a packed item.
+func PipeUnpack[S, P, U, F any](
+	p Pipeline[S, P, F],
+	unpack Unpack[P, U],
+) Pipeline[S, U, F] {
+	return PipeUnnest(p, func(packed *P) ([]U, error) { return unpack(*packed) })
+}
+
+// PipeUnnestFiltered creates a new Pipeline which uses unnested items selected by a filter.
+//
+// A consumer created by Sink reuses a buffer for this stage and must not be used concurrently.
+//
+// # Arguments
+//   - unnest: Passes unnested items which may be required by a filter.
+func PipeUnnestFiltered[S, P, U, F any](
+	p Pipeline[S, P, F],
+	unnest UnnestFiltered[P, U, F],
+) Pipeline[S, U, F] {
+	return pipeNew(p, func(next IterConsumerFiltered[U, F]) IterConsumerFiltered[P, F] {
+		var buf U
+		return func(packed *P, filter *F) (stop bool, e error) {
+			return unnest(packed, filter, &buf, func(item *U) (stop bool, e error) {
+				return next(item, filter)
+			})
+		}
+	})
+}
+
+// PipelineIterNew creates a closure which runs a Pipeline using an iterator.
+//
+// The returned closure is safe for concurrent use if its arguments are
+// (an iterator must not be shared).
+//
+// # Arguments
+//   - iterNext: Checks if an iterator has a next item or not.
+//   - iterGet: Gets a source item.
+//   - iterErr: Gets an error from an iterator.
+//   - p: The stages(created for each run).
+//   - sink: Uses items got by the stages.
+func PipelineIterNew[I, S, T, F any](
+	iterNext func(iter I) (hasNext bool),
+	iterGet func(iter I, item *S) error,
+	iterErr func(iter I) error,
+	p Pipeline[S, T, F],
+	sink IterConsumerFiltered[T, F],
+) func(ctx context.Context, iter I, filter *F) error {
+	var consumeAll func(
+		iter I,
+		filter *F,
+		consumer IterConsumerFiltered[S, F],
+		buf *S,
+	) (stop bool, e error) = IterConsumeManyFilteredNew[I, S, F](iterNext, iterGet, iterErr)
+	return func(ctx context.Context, iter I, filter *F) error {
+		var consumer IterConsumerFiltered[S, F] = p.Sink(sink)
+		var buf S
+		var cc cancelCheck = cancelCheckNew(ctx)
+		_, e := consumeAll(iter, filter, func(item *S, filter *F) (stop bool, e error) {
+			e = cc.check()
+			if nil != e {
+				return true, e
+			}
+			return consumer(item, filter)
+		}, &buf)
+		return e
+	}
+}
+
+// PipelineSliceNew creates a closure which runs a Pipeline using source items.
+//
+// A consumer is built by p.Sink for each run;
+// the closure is safe for concurrent use if the stages and sink are.
+//
+// # Arguments
+//   - p: The stages(created for each run).
+//   - sink: Uses items got by the stages.
+func PipelineSliceNew[S, T, F any](
+	p Pipeline[S, T, F],
+	sink IterConsumerFiltered[T, F],
+) func(ctx context.Context, items []S, filter *F) error {
+	return func(ctx context.Context, items []S, filter *F) error {
+		var consumer IterConsumerFiltered[S, F] = p.Sink(sink)
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for ix := range items {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+			stop, e := consumer(&items[ix], filter)
+			if nil != e {
+				return e
+			}
+			if stop {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// PipelineKeysNew creates a closure which runs a Pipeline using items got by keys.
+//
+// Each run has its own consumer and its own buffer for a source item;
+// the closure is safe for concurrent use if getByKey, the stages and sink are.
+//
+// # Arguments
+//   - getByKey: Gets a source item by a key.
+//   - p: The stages(created for each run).
+//   - sink: Uses items got by the stages.
+func PipelineKeysNew[G, K, S, T, F any](
+	getByKey func(ctx context.Context, con G, key K, item *S) (got bool, e error),
+	p Pipeline[S, T, F],
+	sink IterConsumerFiltered[T, F],
+) func(ctx context.Context, con G, keys []K, filter *F) error {
+	return func(ctx context.Context, con G, keys []K, filter *F) error {
+		var consumer IterConsumerFiltered[S, F] = p.Sink(sink)
+		var buf S
+		var cc cancelCheck = cancelCheckNew(ctx)
+		for _, key := range keys {
+			e := cc.check()
+			if nil != e {
+				return e
+			}
+			got, e := getByKey(ctx, con, key, &buf)
+			if nil != e {
+				return e
+			}
+			if !got {
+				continue
+			}
+			stop, e := consumer(&buf, filter)
+			if nil != e {
+				return e
+			}
+			if stop {
+				return nil
+			}
+		}
+		return nil
+	}
+}
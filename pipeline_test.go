@@ -0,0 +1,139 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	t.Parallel()
+
+	var encodedItems []testEncodedRow = []testEncodedRow{
+		{key: []byte{0x01}, val: []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}},
+		{key: []byte{0x02}, val: []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}},
+		{key: []byte{0x03}, val: []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}},
+	}
+
+	// encoded -> decoded -> packed -> unpacked
+	var p Pipeline[testEncodedRow, testUnpackedRow, uint8] = PipeUnpack(
+		PipeMap(
+			PipeDecode(
+				PipelineNew[testEncodedRow, uint8](),
+				func(e *testEncodedRow) (testDecodedRow, error) { return e.Decode() },
+			).Filter(func(d *testDecodedRow, skipKey *uint8) bool { return d.key != *skipKey }),
+			func(d *testDecodedRow, _ *uint8) (testPackedRow, error) {
+				return testPackedRow{key: d.key, val: d.val}, nil
+			},
+		),
+		Unpack[testPackedRow, testUnpackedRow](func(p testPackedRow) ([]testUnpackedRow, error) {
+			return p.unpack(), nil
+		}),
+	).Filter(func(u *testUnpackedRow, _ *uint8) bool { return 0x45 != u.key&0xff })
+
+	t.Run("PipelineSliceNew", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("all", func(t *testing.T) {
+			t.Parallel()
+
+			var unpacked []testUnpackedRow
+			run := PipelineSliceNew(p, func(u *testUnpackedRow, _ *uint8) (bool, error) {
+				unpacked = append(unpacked, *u)
+				return false, nil
+			})
+
+			var skipKey uint8 = 0x02
+			e := run(context.Background(), encodedItems, &skipKey)
+			t.Run("no error", assertNil(e))
+			t.Run("6 items", assertEq(len(unpacked), 6))
+			t.Run("key", assertEq(unpacked[3].key, 0x0301))
+		})
+
+		t.Run("stop", func(t *testing.T) {
+			t.Parallel()
+
+			var unpacked []testUnpackedRow
+			run := PipelineSliceNew(p, func(u *testUnpackedRow, _ *uint8) (bool, error) {
+				unpacked = append(unpacked, *u)
+				return 4 == len(unpacked), nil
+			})
+
+			var skipKey uint8 = 0x00
+			e := run(context.Background(), encodedItems, &skipKey)
+			t.Run("no error", assertNil(e))
+			t.Run("4 items", assertEq(len(unpacked), 4))
+		})
+
+		t.Run("decode error", func(t *testing.T) {
+			t.Parallel()
+
+			run := PipelineSliceNew(p, func(u *testUnpackedRow, _ *uint8) (bool, error) {
+				return false, nil
+			})
+
+			var skipKey uint8 = 0x00
+			e := run(
+				context.Background(),
+				[]testEncodedRow{{key: []byte{}, val: nil}},
+				&skipKey,
+			)
+			t.Run("error", assertEq(errors.Is(e, testErrorInvalidKey), true))
+		})
+	})
+
+	t.Run("PipelineIterNew", func(t *testing.T) {
+		t.Parallel()
+
+		var unpacked []testUnpackedRow
+		run := PipelineIterNew(
+			func(iter *int) bool { return *iter < len(encodedItems) },
+			func(iter *int, item *testEncodedRow) error {
+				*item = encodedItems[*iter]
+				*iter += 1
+				return nil
+			},
+			func(iter *int) error { return nil },
+			p,
+			func(u *testUnpackedRow, _ *uint8) (bool, error) {
+				unpacked = append(unpacked, *u)
+				return false, nil
+			},
+		)
+
+		var iter int = 0
+		var skipKey uint8 = 0x01
+		e := run(context.Background(), &iter, &skipKey)
+		t.Run("no error", assertNil(e))
+		t.Run("6 items", assertEq(len(unpacked), 6))
+	})
+
+	t.Run("PipelineKeysNew", func(t *testing.T) {
+		t.Parallel()
+
+		var unpacked []testUnpackedRow
+		run := PipelineKeysNew(
+			func(_ context.Context, con []testEncodedRow, key int, item *testEncodedRow) (
+				bool,
+				error,
+			) {
+				if len(con) <= key {
+					return false, nil
+				}
+				*item = con[key]
+				return true, nil
+			},
+			p,
+			func(u *testUnpackedRow, _ *uint8) (bool, error) {
+				unpacked = append(unpacked, *u)
+				return false, nil
+			},
+		)
+
+		var skipKey uint8 = 0x00
+		e := run(context.Background(), encodedItems, []int{2, 5, 0}, &skipKey)
+		t.Run("no error", assertNil(e))
+		t.Run("6 items", assertEq(len(unpacked), 6))
+		t.Run("key order", assertEq(unpacked[0].key, 0x0301))
+	})
+}
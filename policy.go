@@ -0,0 +1,110 @@
+package local
+
+import (
+	"fmt"
+)
+
+// ErrorPolicy decides what to do with an item which can not be decoded(or unpacked).
+type ErrorPolicy uint8
+
+const (
+	// ErrorPolicyFailFast aborts the whole bucket on the first error.
+	ErrorPolicyFailFast ErrorPolicy = iota
+
+	// ErrorPolicySkipCount skips invalid items and counts them.
+	ErrorPolicySkipCount
+
+	// ErrorPolicySkipCollect skips invalid items and collects their errors.
+	ErrorPolicySkipCollect
+)
+
+// IndexUnknown is the index of an item whose position is unknown.
+const IndexUnknown int = -1
+
+// ItemError describes an item which could not be processed.
+type ItemError struct {
+	bucket Bucket
+	index  int
+	key    any
+	err    error
+}
+
+// ItemErrorNew creates an ItemError.
+//
+// # Arguments
+//   - bucket: The bucket which contains the item.
+//   - index: The index of the item in the bucket(or in the scan; IndexUnknown if unknown).
+//   - key: The key of the item(nil if unknown).
+//   - err: The underlying error.
+func ItemErrorNew(bucket Bucket, index int, key any, err error) ItemError {
+	return ItemError{
+		bucket: bucket,
+		index:  index,
+		key:    key,
+		err:    err,
+	}
+}
+
+// Bucket returns the bucket which contains the item.
+func (i ItemError) Bucket() Bucket { return i.bucket }
+
+// Index returns the index of the item(IndexUnknown if unknown).
+func (i ItemError) Index() int { return i.index }
+
+// Key returns the key of the item(nil if unknown).
+func (i ItemError) Key() any { return i.key }
+
+// Unwrap returns the underlying error.
+func (i ItemError) Unwrap() error { return i.err }
+
+func (i ItemError) Error() string {
+	if nil == i.key {
+		return fmt.Sprintf("bucket=%s index=%v: %v", i.bucket.AsString(), i.index, i.err)
+	}
+	if IndexUnknown == i.index {
+		return fmt.Sprintf("bucket=%s key=%v: %v", i.bucket.AsString(), i.key, i.err)
+	}
+	return fmt.Sprintf(
+		"bucket=%s index=%v key=%v: %v",
+		i.bucket.AsString(),
+		i.index,
+		i.key,
+		i.err,
+	)
+}
+
+// ErrorReport contains the number of skipped items and collected errors.
+type ErrorReport struct {
+	skipped int
+	errors  []ItemError
+}
+
+// Skipped returns the number of skipped items.
+func (r *ErrorReport) Skipped() int { return r.skipped }
+
+// Errors returns collected errors(ErrorPolicySkipCollect only).
+func (r *ErrorReport) Errors() []ItemError { return r.errors }
+
+// Clear resets the report so that it can be reused.
+func (r *ErrorReport) Clear() {
+	r.skipped = 0
+	r.errors = r.errors[:0]
+}
+
+// Handle records an error using a policy.
+//
+// # Return value
+//   - e: Non-nil error if the scan must be aborted.
+func (r *ErrorReport) Handle(policy ErrorPolicy, ie ItemError) (e error) {
+	switch policy {
+	case ErrorPolicySkipCount:
+		r.skipped += 1
+		return nil
+	case ErrorPolicySkipCollect:
+		r.skipped += 1
+		r.errors = append(r.errors, ie)
+		return nil
+	default:
+		return ie
+	}
+}
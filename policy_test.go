@@ -0,0 +1,62 @@
+package local
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrorReport", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("fail fast", func(t *testing.T) {
+			t.Parallel()
+
+			var report ErrorReport
+			e := report.Handle(
+				ErrorPolicyFailFast,
+				ItemErrorNew(BucketNew("2023_01_01"), 3, nil, testErrorInvalidVal),
+			)
+			t.Run("error", assertEq(errors.Is(e, testErrorInvalidVal), true))
+			t.Run("no skip", assertEq(report.Skipped(), 0))
+
+			var ie ItemError
+			t.Run("item error", assertEq(errors.As(e, &ie), true))
+			t.Run("bucket", assertEq(ie.Bucket().AsString(), "2023_01_01"))
+			t.Run("index", assertEq(ie.Index(), 3))
+		})
+
+		t.Run("skip count", func(t *testing.T) {
+			t.Parallel()
+
+			var report ErrorReport
+			e := report.Handle(
+				ErrorPolicySkipCount,
+				ItemErrorNew(BucketNew(""), 0, nil, testErrorInvalidVal),
+			)
+			t.Run("no error", assertNil(e))
+			t.Run("skipped", assertEq(report.Skipped(), 1))
+			t.Run("no errors", assertEq(len(report.Errors()), 0))
+		})
+
+		t.Run("skip collect", func(t *testing.T) {
+			t.Parallel()
+
+			var report ErrorReport
+			e := report.Handle(
+				ErrorPolicySkipCollect,
+				ItemErrorNew(BucketNew(""), 0, uint8(0x42), testErrorInvalidKey),
+			)
+			t.Run("no error", assertNil(e))
+			t.Run("skipped", assertEq(report.Skipped(), 1))
+			t.Run("single error", assertEq(len(report.Errors()), 1))
+			t.Run("key", assertEq(report.Errors()[0].Key().(uint8), 0x42))
+
+			report.Clear()
+			t.Run("cleared", assertEq(report.Skipped(), 0))
+			t.Run("no errors", assertEq(len(report.Errors()), 0))
+		})
+	})
+}
@@ -0,0 +1,154 @@
+package local
+
+import (
+	"context"
+	"sync"
+)
+
+// BufferPool provides buffers which can be used by several goroutines.
+type BufferPool[T any] struct {
+	pool sync.Pool
+}
+
+// BufferPoolNew creates a BufferPool which creates zero buffers.
+func BufferPoolNew[T any]() *BufferPool[T] {
+	return &BufferPool[T]{
+		pool: sync.Pool{
+			New: func() any { return new(T) },
+		},
+	}
+}
+
+// Get gets a buffer which may contain a value used before.
+func (p *BufferPool[T]) Get() *T { return p.pool.Get().(*T) }
+
+// Put returns a buffer which must not be used by the caller anymore.
+func (p *BufferPool[T]) Put(buf *T) { p.pool.Put(buf) }
+
+// GetByKeyNewDecodedPooled creates a closure like GetByKeyNewDecoded
+// which can be used concurrently.
+//
+// A buffer is taken from the pool for each call;
+// decoded items must not refer to the buffer.
+//
+// # Arguments
+//   - getEncodedByKey: Gets an encoded item(must be safe for concurrent use).
+//   - decoder: Gets a decoded item from an encoded item(must be safe for concurrent use).
+//   - pool: Provides buffers to save encoded items.
+func GetByKeyNewDecodedPooled[G, K, E, D any](
+	getEncodedByKey func(ctx context.Context, con G, key K, encoded *E) (got bool, e error),
+	decoder Decode[*E, D],
+	pool *BufferPool[E],
+) func(ctx context.Context, con G, key K, decoded *D) (got bool, e error) {
+	return func(ctx context.Context, con G, key K, decoded *D) (got bool, e error) {
+		var buf *E = pool.Get()
+		defer pool.Put(buf)
+		got, e = getEncodedByKey(ctx, con, key, buf)
+		if nil != e {
+			return false, e
+		}
+		if !got {
+			return false, nil
+		}
+		dec, e := decoder(buf)
+		if nil != e {
+			return false, e
+		}
+		*decoded = dec
+		return true, nil
+	}
+}
+
+// GetByKeyDecodedNewPooled creates a new closure like GetByKeyDecodedNew
+// which can be used concurrently.
+//
+// A buffer is taken from the pool for each call;
+// decoded items must not refer to the buffer.
+//
+// # Arguments
+//   - getEncodedByKey: Gets an encoded value(must be safe for concurrent use).
+//   - decoder: Gets a decoded value from an encoded value(must be safe for concurrent use).
+//   - pool: Provides buffers to save encoded items.
+//   - filterDecoded: Checks if a decoded item must be used or not.
+func GetByKeyDecodedNewPooled[G, B, F, K, E, D any](
+	getEncodedByKey GetByKey[G, B, F, K, E],
+	decoder func(encoded *E) (decoded D, e error),
+	pool *BufferPool[E],
+	filterDecoded func(decoded *D, filter *F) (keep bool),
+) GetByKey[G, B, F, K, D] {
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		key K,
+		val *D,
+		filter *F,
+	) (got bool, e error) {
+		var buf *E = pool.Get()
+		defer pool.Put(buf)
+		got, e = getEncodedByKey(ctx, con, bucket, key, buf, filter)
+		if nil != e || !got {
+			return false, e
+		}
+		decoded, e := decoder(buf)
+		if nil != e {
+			return false, e
+		}
+		var keep bool = filterDecoded(&decoded, filter)
+		if !keep {
+			return false, nil
+		}
+		*val = decoded
+		return true, nil
+	}
+}
+
+// GetByKeyLazyNewPooled creates a new closure like GetByKeyLazyNew
+// which can be used concurrently.
+//
+// A buffer is taken from the pool for each call;
+// decoded items must not refer to the buffer.
+//
+// # Arguments
+//   - getEncodedByKey: Gets an encoded value(must be safe for concurrent use).
+//   - project: Gets a cheap projection from an encoded value.
+//   - filterProjected: Checks if a projected item must be used or not.
+//   - decoder: Gets a decoded value from an encoded value.
+//   - pool: Provides buffers to save encoded items.
+func GetByKeyLazyNewPooled[G, B, F, K, E, R, D any](
+	getEncodedByKey GetByKey[G, B, F, K, E],
+	project Projection[E, R],
+	filterProjected func(projected *R, filter *F) (keep bool),
+	decoder func(encoded *E) (decoded D, e error),
+	pool *BufferPool[E],
+) GetByKey[G, B, F, K, D] {
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		key K,
+		val *D,
+		filter *F,
+	) (got bool, e error) {
+		var buf *E = pool.Get()
+		defer pool.Put(buf)
+		got, e = getEncodedByKey(ctx, con, bucket, key, buf, filter)
+		if nil != e || !got {
+			return false, e
+		}
+		projected, e := project(buf)
+		if nil != e {
+			return false, e
+		}
+		var keep bool = filterProjected(&projected, filter)
+		if !keep {
+			return false, nil
+		}
+		decoded, e := decoder(buf)
+		if nil != e {
+			return false, e
+		}
+		*val = decoded
+		return true, nil
+	}
+}
@@ -0,0 +1,140 @@
+package local
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+)
+
+func testPoolRun(workers int, keys int, get func(key uint32) (got bool, val uint32, e error)) (
+	bad int,
+	e error,
+) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for k := w; k < keys; k += workers {
+				got, val, err := get(uint32(k))
+				mu.Lock()
+				if nil != err && nil == e {
+					e = err
+				}
+				if got && val != uint32(k)*3 {
+					bad += 1
+				}
+				if !got && 0 != k%5 {
+					bad += 1
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	return
+}
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	// every 5th key is missing.
+	var getEncoded func(key uint32, encoded *[]byte) (got bool) = func(
+		key uint32,
+		encoded *[]byte,
+	) (got bool) {
+		if 0 == key%5 {
+			return false
+		}
+		*encoded = binary.BigEndian.AppendUint32((*encoded)[:0], key*3)
+		return true
+	}
+
+	var decoder func(encoded *[]byte) (uint32, error) = func(encoded *[]byte) (uint32, error) {
+		return binary.BigEndian.Uint32(*encoded), nil
+	}
+
+	t.Run("GetByKeyNewDecodedPooled", func(t *testing.T) {
+		t.Parallel()
+
+		f := GetByKeyNewDecodedPooled(
+			func(_ context.Context, _ uint8, key uint32, encoded *[]byte) (bool, error) {
+				return getEncoded(key, encoded), nil
+			},
+			Decode[*[]byte, uint32](decoder),
+			BufferPoolNew[[]byte](),
+		)
+		bad, e := testPoolRun(8, 4096, func(key uint32) (got bool, val uint32, e error) {
+			got, e = f(context.Background(), 0, key, &val)
+			return
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("no bad items", assertEq(bad, 0))
+	})
+
+	t.Run("GetByKeyDecodedNewPooled", func(t *testing.T) {
+		t.Parallel()
+
+		f := GetByKeyDecodedNewPooled(
+			GetByKey[uint8, string, uint8, uint32, []byte](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				key uint32,
+				encoded *[]byte,
+				_ *uint8,
+			) (bool, error) {
+				return getEncoded(key, encoded), nil
+			}),
+			decoder,
+			BufferPoolNew[[]byte](),
+			func(_ *uint32, _ *uint8) bool { return true },
+		)
+		bad, e := testPoolRun(8, 4096, func(key uint32) (got bool, val uint32, e error) {
+			got, e = f(context.Background(), 0, nil, key, &val, nil)
+			return
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("no bad items", assertEq(bad, 0))
+	})
+
+	t.Run("GetByKeyLazyNewPooled", func(t *testing.T) {
+		t.Parallel()
+
+		f := GetByKeyLazyNewPooled(
+			GetByKey[uint8, string, uint8, uint32, []byte](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				key uint32,
+				encoded *[]byte,
+				_ *uint8,
+			) (bool, error) {
+				return getEncoded(key, encoded), nil
+			}),
+			Projection[[]byte, uint8](func(encoded *[]byte) (uint8, error) {
+				return (*encoded)[3], nil
+			}),
+			func(_ *uint8, _ *uint8) bool { return true },
+			decoder,
+			BufferPoolNew[[]byte](),
+		)
+		bad, e := testPoolRun(8, 4096, func(key uint32) (got bool, val uint32, e error) {
+			got, e = f(context.Background(), 0, nil, key, &val, nil)
+			return
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("no bad items", assertEq(bad, 0))
+	})
+
+	t.Run("BufferPool", func(t *testing.T) {
+		t.Parallel()
+
+		var pool *BufferPool[[]byte] = BufferPoolNew[[]byte]()
+		var buf *[]byte = pool.Get()
+		t.Run("zero", assertEq(len(*buf), 0))
+		pool.Put(buf)
+	})
+}
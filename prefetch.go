@@ -0,0 +1,83 @@
+package local
+
+import (
+	"context"
+	"errors"
+)
+
+var errPrefetchStop error = errors.New("prefetch stopped")
+
+type prefetched[V any] struct {
+	val V
+	got bool
+}
+
+// GetByKeysNewPrefetch creates a closure like GetByKeysNew
+// which keeps up to workers getByKey calls in flight.
+//
+// Items are passed to the consumer in key order.
+// Each getByKey call uses its own buffer; items are copied into buf before consumed.
+// Outstanding calls are cancelled when the consumer stops or fails.
+// Workers are started for each call; the closure is safe for concurrent use if getKeys is.
+//
+// # Arguments
+//   - getKeys: Gets keys for items.
+//   - getByKey: Gets an item by a key(must be safe for concurrent use).
+//   - workers: The number of in-flight calls(at least 1).
+//   - window: Max number of keys fetched but not yet consumed(at least workers).
+func GetByKeysNewPrefetch[G, K, F, B, V any](
+	getKeys GetKeys[G, B, F, K],
+	getByKey GetByKey[G, B, F, K, V],
+	workers int,
+	window int,
+) Got2Consumer[G, K, F, B, V] {
+	var cfg ParallelConfig = ParallelConfigNew(workers, ParallelOrderInput).WithWindow(window)
+	return func(
+		ctx context.Context,
+		con G,
+		bucket *B,
+		filter *F,
+		buf *V,
+		consumer func(val *V, filter *F) (stop bool, e error),
+	) error {
+		keys, e := getKeys(ctx, con, bucket, filter)
+		if nil != e {
+			return e
+		}
+		var ix int = 0
+		e = parallelDecode(
+			ctx,
+			cfg,
+			func(key *K) (got bool, e error) {
+				if len(keys) <= ix {
+					return false, nil
+				}
+				*key = keys[ix]
+				ix += 1
+				return true, nil
+			},
+			func(ctx context.Context, key *K) (p prefetched[V], e error) {
+				p.got, e = getByKey(ctx, con, bucket, *key, &p.val, filter)
+				return
+			},
+			func(p prefetched[V]) error {
+				if !p.got {
+					return nil
+				}
+				*buf = p.val
+				stop, e := consumer(buf, filter)
+				if nil != e {
+					return e
+				}
+				if stop {
+					return errPrefetchStop
+				}
+				return nil
+			},
+		)
+		if errors.Is(e, errPrefetchStop) {
+			return nil
+		}
+		return e
+	}
+}
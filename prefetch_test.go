@@ -0,0 +1,161 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrefetch(t *testing.T) {
+	t.Parallel()
+
+	var getKeys GetKeys[uint8, string, uint8, int] = func(
+		_ context.Context,
+		_ uint8,
+		_ *string,
+		_ *uint8,
+	) ([]int, error) {
+		var keys []int
+		for i := 0; i < 32; i++ {
+			keys = append(keys, i)
+		}
+		return keys, nil
+	}
+
+	t.Run("key order", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var inFlight int = 0
+		var peak int = 0
+		f := GetByKeysNewPrefetch(
+			getKeys,
+			GetByKey[uint8, string, uint8, int, int](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				key int,
+				val *int,
+				_ *uint8,
+			) (got bool, e error) {
+				mu.Lock()
+				inFlight += 1
+				if peak < inFlight {
+					peak = inFlight
+				}
+				mu.Unlock()
+
+				time.Sleep(time.Duration(key%3) * time.Millisecond)
+				*val = key
+
+				mu.Lock()
+				inFlight -= 1
+				mu.Unlock()
+				return 0 != key%4, nil
+			}),
+			4,
+			8,
+		)
+
+		var vals []int
+		var buf int
+		e := f(context.Background(), 0, nil, nil, &buf, func(
+			val *int,
+			_ *uint8,
+		) (stop bool, e error) {
+			vals = append(vals, *val)
+			return false, nil
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("24 items", assertEq(len(vals), 24))
+		t.Run("bounded", assertEq(peak <= 4, true))
+
+		var sorted bool = true
+		for ix := 1; ix < len(vals); ix++ {
+			sorted = sorted && vals[ix-1] < vals[ix]
+		}
+		t.Run("sorted", assertEq(sorted, true))
+	})
+
+	t.Run("stop cancels outstanding calls", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var cancelled int = 0
+		f := GetByKeysNewPrefetch(
+			getKeys,
+			GetByKey[uint8, string, uint8, int, int](func(
+				ctx context.Context,
+				_ uint8,
+				_ *string,
+				key int,
+				val *int,
+				_ *uint8,
+			) (got bool, e error) {
+				if 0 == key {
+					*val = key
+					return true, nil
+				}
+				<-ctx.Done()
+				mu.Lock()
+				cancelled += 1
+				mu.Unlock()
+				return false, ctx.Err()
+			}),
+			3,
+			6,
+		)
+
+		var vals []int
+		var buf int
+		e := f(context.Background(), 0, nil, nil, &buf, func(
+			val *int,
+			_ *uint8,
+		) (stop bool, e error) {
+			vals = append(vals, *val)
+			return true, nil
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("single item", assertEq(len(vals), 1))
+		t.Run("outstanding calls cancelled", assertEq(0 < cancelled, true))
+		t.Run("bounded", assertEq(cancelled <= 5, true))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		f := GetByKeysNewPrefetch(
+			getKeys,
+			GetByKey[uint8, string, uint8, int, int](func(
+				_ context.Context,
+				_ uint8,
+				_ *string,
+				key int,
+				val *int,
+				_ *uint8,
+			) (got bool, e error) {
+				if 5 == key {
+					return false, testErrorInvalidKey
+				}
+				*val = key
+				return true, nil
+			}),
+			2,
+			0,
+		)
+
+		var vals []int
+		var buf int
+		e := f(context.Background(), 0, nil, nil, &buf, func(
+			val *int,
+			_ *uint8,
+		) (stop bool, e error) {
+			vals = append(vals, *val)
+			return false, nil
+		})
+		t.Run("error", assertEq(errors.Is(e, testErrorInvalidKey), true))
+		t.Run("5 items", assertEq(len(vals), 5))
+	})
+}
@@ -43,6 +43,8 @@ func FilterRemote[V, F any](
 
 // FilterRemoteNew creates a new closure which gets filtered rows.
 //
+// The closure keeps no state; it is safe for concurrent use if its arguments are.
+//
 // # Arguments
 //
 //   - all: Gets all values in a bucket.
@@ -102,6 +104,8 @@ func (s ScanEstimate) UseIxScanByCount(limit float64) (useIxScan bool) {
 
 // PushdownNewByIxScanLimit creates a PushDown which uses a ScanEstimate.
 //
+// The PushDown is as safe for concurrent use as filter2scan.
+//
 // # Arguments
 //   - limit: Max number of scans using indices(exclusive).
 //   - filter2scan: Gets a ScanEstimate by a filter.
@@ -141,6 +145,8 @@ func (s ScanEstimates) UseIxScan() bool { return s.toIxScanCost() < s.toSqScanCo
 
 // PushdownNewByCost creates a PushDown which uses a ScanEstimates.
 //
+// The PushDown is as safe for concurrent use as filter2estimates.
+//
 // # Arguments
 //   - filter2estimates: Creates a ScanEstimates from a filter.
 func PushdownNewByCost[F any](
@@ -0,0 +1,837 @@
+package local
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	testRaceKeys    int = 32
+	testRaceWorkers int = 8
+	testRaceRuns    int = 16
+)
+
+// testRaceCheck runs a shared closure from many goroutines(use go test -race).
+//
+// # Arguments
+//   - want: The sum of items added by a run.
+//   - shared: Creates a closure once; the closure is used by all goroutines.
+func testRaceCheck(want int, shared func(add func(v int)) (run func() error)) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		var total int64 = 0
+		var run func() error = shared(func(v int) { atomic.AddInt64(&total, int64(v)) })
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		var e error
+		wg.Add(testRaceWorkers)
+		for w := 0; w < testRaceWorkers; w++ {
+			go func() {
+				defer wg.Done()
+				for r := 0; r < testRaceRuns; r++ {
+					err := run()
+					mu.Lock()
+					if nil != err && nil == e {
+						e = err
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		t.Run("no error", assertNil(e))
+		t.Run("all items", assertEq(
+			atomic.LoadInt64(&total),
+			int64(want*testRaceWorkers*testRaceRuns),
+		))
+	}
+}
+
+type testRaceIter struct {
+	items [][]int
+	ix    int
+}
+
+func (i *testRaceIter) next() bool { return i.ix < len(i.items) }
+
+func (i *testRaceIter) get(buf *[]int) error {
+	*buf = i.items[i.ix]
+	i.ix += 1
+	return nil
+}
+
+func (i *testRaceIter) err() error { return nil }
+
+func testRaceSum(items []int) (sum int) {
+	for _, item := range items {
+		sum += item
+	}
+	return
+}
+
+func TestRace(t *testing.T) {
+	t.Parallel()
+
+	// key k has a packed item {10k, 10k+1, 10k+2}.
+	var store map[int][]int = make(map[int][]int, testRaceKeys)
+	var items [][]int
+	var want int = 0
+	for k := 0; k < testRaceKeys; k++ {
+		store[k] = []int{10 * k, 10*k + 1, 10*k + 2}
+		items = append(items, store[k])
+		want += testRaceSum(store[k])
+	}
+
+	// the sum of all keys.
+	var wantKeys int = testRaceKeys * (testRaceKeys - 1) / 2
+
+	// the packed item of the key 0 is invalid for decode and unpack(its sum is 3).
+	var wantValid int = want - 3
+
+	var all func(context.Context, Bucket) ([][]int, error) = func(
+		_ context.Context,
+		_ Bucket,
+	) ([][]int, error) {
+		return append([][]int(nil), items...), nil
+	}
+
+	var decode Decode[[]int, int] = func(packed []int) (int, error) {
+		if 0 == packed[0] {
+			return 0, testErrorInvalidKey
+		}
+		return testRaceSum(packed), nil
+	}
+
+	var unpack Unpack[[]int, int] = func(packed []int) ([]int, error) {
+		if 0 == packed[0] {
+			return nil, testErrorInvalidKey
+		}
+		return packed, nil
+	}
+
+	var unnest Unnest[[]int, int] = func(packed *[]int) ([]int, error) { return *packed, nil }
+
+	var getKeys GetKeys[map[int][]int, uint8, int, int] = func(
+		_ context.Context,
+		_ map[int][]int,
+		_ *uint8,
+		_ *int,
+	) ([]int, error) {
+		var keys []int
+		for k := testRaceKeys - 1; 0 <= k; k-- {
+			keys = append(keys, k)
+		}
+		return keys, nil
+	}
+
+	var getByKey GetByKey[map[int][]int, uint8, int, int, []int] = func(
+		_ context.Context,
+		con map[int][]int,
+		_ *uint8,
+		key int,
+		val *[]int,
+		_ *int,
+	) (got bool, e error) {
+		*val, got = con[key]
+		return got, nil
+	}
+
+	getPacked := func(ctx context.Context, con map[int][]int, key int, packed *[]int) (bool, error) {
+		return getByKey(ctx, con, nil, key, packed, nil)
+	}
+
+	keys := func() []int {
+		keys, _ := getKeys(context.Background(), store, nil, nil)
+		return keys
+	}
+
+	keepPacked := func(_ *[]int, _ *int) bool { return true }
+	keepItem := func(_ *int, _ *int) bool { return true }
+	keepChild := func(_ *[]int, _ *int, _ int, _ *int) bool { return true }
+	less := func(a, b int) bool { return a < b }
+	everything := func(_ *int) SortedRange[int] { return SortedRangeNew(0, 1<<30, less) }
+
+	// a consumer of unnested items.
+	consumeNew := func(add func(int)) IterConsumer[int] {
+		return func(item *int) (stop bool, e error) {
+			add(*item)
+			return false, nil
+		}
+	}
+
+	// a run of a Got2Consumer which gets packed items.
+	got2run := func(
+		f Got2Consumer[map[int][]int, int, int, uint8, []int],
+		add func(int),
+	) func() error {
+		return func() error {
+			var buf []int
+			return f(context.Background(), store, nil, nil, &buf, func(
+				val *[]int,
+				_ *int,
+			) (stop bool, e error) {
+				add(testRaceSum(*val))
+				return false, nil
+			})
+		}
+	}
+
+	t.Run("Decode.NewAll", testRaceCheck(want, func(add func(int)) func() error {
+		f := Decode[[]int, int](func(packed []int) (int, error) {
+			return testRaceSum(packed), nil
+		}).NewAll(all)
+		return func() error {
+			decoded, e := f(context.Background(), BucketNew("race"))
+			add(testRaceSum(decoded))
+			return e
+		}
+	}))
+
+	t.Run("RemoteFilterNewDecoded", testRaceCheck(want, func(add func(int)) func() error {
+		f := RemoteFilterNewDecoded(
+			Decode[[]int, int](func(packed []int) (int, error) { return testRaceSum(packed), nil }),
+			func(ctx context.Context, b Bucket, _ int) ([][]int, error) { return all(ctx, b) },
+		)
+		return func() error {
+			decoded, e := f(context.Background(), BucketNew("race"), 0)
+			add(testRaceSum(decoded))
+			return e
+		}
+	}))
+
+	t.Run("Unpack.NewAll", testRaceCheck(want, func(add func(int)) func() error {
+		f := Unpack[[]int, int](func(packed []int) ([]int, error) { return packed, nil }).NewAll(all)
+		return func() error {
+			unpacked, e := f(context.Background(), BucketNew("race"))
+			add(testRaceSum(unpacked))
+			return e
+		}
+	}))
+
+	t.Run("RemoteFilterNewUnpacked", testRaceCheck(want, func(add func(int)) func() error {
+		f := RemoteFilterNewUnpacked(
+			Unpack[[]int, int](func(packed []int) ([]int, error) { return packed, nil }),
+			func(ctx context.Context, b Bucket, _ int) ([][]int, error) { return all(ctx, b) },
+		)
+		return func() error {
+			unpacked, e := f(context.Background(), BucketNew("race"), 0)
+			add(testRaceSum(unpacked))
+			return e
+		}
+	}))
+
+	t.Run("Iter2UnpackedNew", testRaceCheck(want, func(add func(int)) func() error {
+		f := Iter2UnpackedNew(
+			func(packed *[]int) (int, error) { return testRaceSum(*packed), nil },
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+		)
+		return func() error {
+			unpacked, e := f(context.Background(), &testRaceIter{items: items})
+			add(testRaceSum(unpacked))
+			return e
+		}
+	}))
+
+	t.Run("Iter2UnpackedWithFilterNew", testRaceCheck(want, func(add func(int)) func() error {
+		f := Iter2UnpackedWithFilterNew(
+			func(packed *[]int) (int, error) { return testRaceSum(*packed), nil },
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+			keepPacked,
+			keepItem,
+		)
+		return func() error {
+			unpacked, e := f(context.Background(), &testRaceIter{items: items}, nil)
+			add(testRaceSum(unpacked))
+			return e
+		}
+	}))
+
+	t.Run("Iter2ConsumerNewUnnested", testRaceCheck(want, func(add func(int)) func() error {
+		f := Iter2ConsumerNewUnnested(
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+			unnest,
+			keepPacked,
+			keepItem,
+			consumeNew(add),
+		)
+		return func() error {
+			var buf []int
+			return f(context.Background(), &testRaceIter{items: items}, &buf, nil)
+		}
+	}))
+
+	t.Run("ConsumerDecodedNew", testRaceCheck(want, func(add func(int)) func() error {
+		consumer := ConsumerDecodedNew(
+			func(decoded *int, _ *int) (stop bool, e error) {
+				add(*decoded)
+				return false, nil
+			},
+			func(packed *[]int) (int, error) { return testRaceSum(*packed), nil },
+			keepPacked,
+		)
+		return func() error {
+			for ix := range items {
+				var item []int = items[ix]
+				_, e := consumer(&item, nil)
+				if nil != e {
+					return e
+				}
+			}
+			return nil
+		}
+	}))
+
+	t.Run("ConsumerUnpackedNew", testRaceCheck(want, func(add func(int)) func() error {
+		consumer := ConsumerUnpackedNew(
+			func(unpacked *int, _ *int) (stop bool, e error) {
+				add(*unpacked)
+				return false, nil
+			},
+			unnest,
+			keepPacked,
+		)
+		return func() error {
+			for ix := range items {
+				var item []int = items[ix]
+				_, e := consumer(&item, nil)
+				if nil != e {
+					return e
+				}
+			}
+			return nil
+		}
+	}))
+
+	t.Run("GetByKeysNew", testRaceCheck(want, func(add func(int)) func() error {
+		return got2run(GetByKeysNew(getKeys, getByKey), add)
+	}))
+
+	t.Run("GetWithPlanNew", testRaceCheck(want, func(add func(int)) func() error {
+		var byKeys Got2Consumer[map[int][]int, int, int, uint8, []int] = GetByKeysNew(
+			getKeys,
+			getByKey,
+		)
+		return got2run(GetWithPlanNew(byKeys, byKeys, func(_ *int) bool { return false }), add)
+	}))
+
+	t.Run("GetByKeysNewUnnested", testRaceCheck(want, func(add func(int)) func() error {
+		f := GetByKeysNewUnnested(getPacked, unnest, keepPacked, keepItem, consumeNew(add))
+		return func() error {
+			var buf []int
+			return f(context.Background(), keys(), store, &buf, nil)
+		}
+	}))
+
+	t.Run("Decode.NewAllWithPolicy", testRaceCheck(wantValid+1, func(add func(int)) func() error {
+		f := decode.NewAllWithPolicy(all, ErrorPolicySkipCollect)
+		return func() error {
+			decoded, report, e := f(context.Background(), BucketNew("race"))
+			add(testRaceSum(decoded) + len(report.Errors()))
+			return e
+		}
+	}))
+
+	t.Run("RemoteFilterNewDecodedWithPolicy", testRaceCheck(wantValid+1, func(
+		add func(int),
+	) func() error {
+		f := RemoteFilterNewDecodedWithPolicy(
+			decode,
+			func(ctx context.Context, b Bucket, _ int) ([][]int, error) { return all(ctx, b) },
+			ErrorPolicySkipCount,
+		)
+		return func() error {
+			decoded, report, e := f(context.Background(), BucketNew("race"), 0)
+			add(testRaceSum(decoded) + report.Skipped())
+			return e
+		}
+	}))
+
+	t.Run("Unpack.NewAllWithPolicy", testRaceCheck(wantValid+1, func(add func(int)) func() error {
+		f := unpack.NewAllWithPolicy(all, ErrorPolicySkipCount)
+		return func() error {
+			unpacked, report, e := f(context.Background(), BucketNew("race"))
+			add(testRaceSum(unpacked) + report.Skipped())
+			return e
+		}
+	}))
+
+	t.Run("Decode.NewAllWithDeadLetter", testRaceCheck(wantValid+1, func(
+		add func(int),
+	) func() error {
+		f := decode.NewAllWithDeadLetter(
+			all,
+			func(_ *[]int, ie ItemError) error {
+				add(ie.Index() + 1)
+				return nil
+			},
+			nil,
+		)
+		return func() error {
+			decoded, e := f(context.Background(), BucketNew("race"))
+			add(testRaceSum(decoded))
+			return e
+		}
+	}))
+
+	t.Run("Unpack.NewAllWithDeadLetter", testRaceCheck(wantValid+1, func(
+		add func(int),
+	) func() error {
+		f := unpack.NewAllWithDeadLetter(
+			all,
+			func(_ *[]int, ie ItemError) error {
+				add(ie.Index() + 1)
+				return nil
+			},
+			func(packed *[]int) any { return (*packed)[0] / 10 },
+		)
+		return func() error {
+			unpacked, e := f(context.Background(), BucketNew("race"))
+			add(testRaceSum(unpacked))
+			return e
+		}
+	}))
+
+	t.Run("Unnest.WithDeadLetter", testRaceCheck(wantValid+1, func(add func(int)) func() error {
+		var withDeadLetter Unnest[[]int, int] = Unnest[[]int, int](func(
+			packed *[]int,
+		) ([]int, error) {
+			return unpack(*packed)
+		}).WithDeadLetter(
+			func(_ *[]int, _ ItemError) error {
+				add(1)
+				return nil
+			},
+			nil,
+		)(BucketNew("race"))
+		f := Iter2ConsumerNewUnnested(
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+			withDeadLetter,
+			keepPacked,
+			keepItem,
+			consumeNew(add),
+		)
+		return func() error {
+			var buf []int
+			return f(context.Background(), &testRaceIter{items: items}, &buf, nil)
+		}
+	}))
+
+	t.Run("RemoteFilterNewDecodedWithDeadLetter", testRaceCheck(wantValid+1, func(
+		add func(int),
+	) func() error {
+		f := RemoteFilterNewDecodedWithDeadLetter(
+			decode,
+			func(ctx context.Context, b Bucket, _ int) ([][]int, error) { return all(ctx, b) },
+			func(_ *[]int, ie ItemError) error {
+				add(ie.Index() + 1)
+				return nil
+			},
+			nil,
+		)
+		return func() error {
+			decoded, e := f(context.Background(), BucketNew("race"), 0)
+			add(testRaceSum(decoded))
+			return e
+		}
+	}))
+
+	t.Run("ConsumerDecodedNewWithDeadLetter", testRaceCheck(wantValid+1, func(
+		add func(int),
+	) func() error {
+		factory := ConsumerDecodedNewWithDeadLetter(
+			func(decoded *int, _ *int) (stop bool, e error) {
+				add(*decoded)
+				return false, nil
+			},
+			DecodeNewPtr(decode),
+			keepPacked,
+			func(_ *[]int, ie ItemError) error {
+				add(ie.Index() + 1)
+				return nil
+			},
+			nil,
+		)
+		return func() error {
+			consumer := factory(BucketNew("race"))
+			for ix := range items {
+				var item []int = items[ix]
+				_, e := consumer(&item, nil)
+				if nil != e {
+					return e
+				}
+			}
+			return nil
+		}
+	}))
+
+	t.Run("GetByKeysNewUnnestedWithDeadLetter", testRaceCheck(wantValid+1, func(
+		add func(int),
+	) func() error {
+		f := GetByKeysNewUnnestedWithDeadLetter(
+			getPacked,
+			Unnest[[]int, int](func(packed *[]int) ([]int, error) { return unpack(*packed) }),
+			keepPacked,
+			keepItem,
+			consumeNew(add),
+			func(_ *[]int, _ ItemError) error {
+				add(1)
+				return nil
+			},
+		)(BucketNew("race"))
+		return func() error {
+			var buf []int
+			return f(context.Background(), keys(), store, &buf, nil)
+		}
+	}))
+
+	t.Run("ConsumerDecodedNewWithPolicy", testRaceCheck(wantValid+1, func(
+		add func(int),
+	) func() error {
+		factory := ConsumerDecodedNewWithPolicy(
+			func(decoded *int, _ *int) (stop bool, e error) {
+				add(*decoded)
+				return false, nil
+			},
+			DecodeNewPtr(decode),
+			keepPacked,
+			ErrorPolicySkipCollect,
+		)
+		return func() error {
+			var report ErrorReport
+			consumer := factory(BucketNew("race"), &report)
+			for ix := range items {
+				var item []int = items[ix]
+				_, e := consumer(&item, nil)
+				if nil != e {
+					return e
+				}
+			}
+			for _, ie := range report.Errors() {
+				add(ie.Index() + 1)
+			}
+			return nil
+		}
+	}))
+
+	t.Run("Decode.NewAllParallel", testRaceCheck(want, func(add func(int)) func() error {
+		f := DecodeCompose(
+			Decode[[]int, []int](func(packed []int) ([]int, error) { return packed, nil }),
+			Decode[[]int, int](func(packed []int) (int, error) { return testRaceSum(packed), nil }),
+		).NewAllParallel(all, ParallelConfigNew(4, ParallelOrderInput).WithWindow(8))
+		return func() error {
+			decoded, e := f(context.Background(), BucketNew("race"))
+			add(testRaceSum(decoded))
+			return e
+		}
+	}))
+
+	t.Run("Iter2UnpackedNewParallel", testRaceCheck(want, func(add func(int)) func() error {
+		f := Iter2UnpackedNewParallel(
+			func(packed *[]int) (int, error) { return testRaceSum(*packed), nil },
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+			ParallelConfigNew(4, ParallelOrderArrival),
+		)
+		return func() error {
+			unpacked, e := f(context.Background(), &testRaceIter{items: items})
+			add(testRaceSum(unpacked))
+			return e
+		}
+	}))
+
+	t.Run("Iter2ConsumerNewUnnestedEach", testRaceCheck(want, func(add func(int)) func() error {
+		f := Iter2ConsumerNewUnnestedEach(
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+			unnest.ToEach().ToUnnest().ToEach(),
+			keepPacked,
+			keepItem,
+			consumeNew(add),
+		)
+		return func() error {
+			var buf []int
+			return f(context.Background(), &testRaceIter{items: items}, &buf, nil)
+		}
+	}))
+
+	t.Run("GetByKeysNewUnnestedEach", testRaceCheck(want, func(add func(int)) func() error {
+		f := GetByKeysNewUnnestedEach(getPacked, unnest.ToEach(), keepPacked, keepItem, consumeNew(add))
+		return func() error {
+			var buf []int
+			return f(context.Background(), keys(), store, &buf, nil)
+		}
+	}))
+
+	t.Run("Iter2ConsumerNewUnnestedWithParent", testRaceCheck(want, func(
+		add func(int),
+	) func() error {
+		f := Iter2ConsumerNewUnnestedWithParent(
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+			unnest,
+			keepPacked,
+			keepChild,
+			func(_ *[]int, child *int, _ int) (stop bool, e error) {
+				add(*child)
+				return false, nil
+			},
+		)
+		return func() error {
+			var buf []int
+			return f(context.Background(), &testRaceIter{items: items}, &buf, nil)
+		}
+	}))
+
+	t.Run("GetByKeysNewUnnestedWithParent", testRaceCheck(want, func(
+		add func(int),
+	) func() error {
+		f := GetByKeysNewUnnestedWithParent(
+			getPacked,
+			unnest,
+			keepPacked,
+			keepChild,
+			func(parent *[]int, _ *int, ordinal int) (stop bool, e error) {
+				add((*parent)[ordinal])
+				return false, nil
+			},
+		)
+		return func() error {
+			var buf []int
+			return f(context.Background(), keys(), store, &buf, nil)
+		}
+	}))
+
+	t.Run("ConsumerUnnestedWithParentNew", testRaceCheck(want, func(
+		add func(int),
+	) func() error {
+		consumer := ConsumerUnnestedWithParentNew(
+			unnest,
+			keepPacked,
+			keepChild,
+			func(_ *[]int, child *int, _ int) (stop bool, e error) {
+				add(*child)
+				return false, nil
+			},
+		)
+		return func() error {
+			for ix := range items {
+				var item []int = items[ix]
+				_, e := consumer(&item, nil)
+				if nil != e {
+					return e
+				}
+			}
+			return nil
+		}
+	}))
+
+	t.Run("ConsumerLazyNew", testRaceCheck(want, func(add func(int)) func() error {
+		consumer := ConsumerLazyNew(
+			func(decoded *int, _ *int) (stop bool, e error) {
+				add(*decoded)
+				return false, nil
+			},
+			func(packed *[]int) (int, error) { return (*packed)[0], nil },
+			func(_ *int, _ *int) bool { return true },
+			func(packed *[]int) (int, error) { return testRaceSum(*packed), nil },
+		)
+		return func() error {
+			for ix := range items {
+				var item []int = items[ix]
+				_, e := consumer(&item, nil)
+				if nil != e {
+					return e
+				}
+			}
+			return nil
+		}
+	}))
+
+	t.Run("PipelineSliceNew", testRaceCheck(want, func(add func(int)) func() error {
+		f := PipelineSliceNew(
+			PipeUnnestFiltered(
+				PipelineNew[[]int, int](),
+				UnnestChainNew(unnest, keepPacked).ToFiltered(),
+			),
+			func(item *int, _ *int) (stop bool, e error) {
+				add(*item)
+				return false, nil
+			},
+		)
+		return func() error { return f(context.Background(), items, nil) }
+	}))
+
+	t.Run("PipelineIterNew", testRaceCheck(want, func(add func(int)) func() error {
+		f := PipelineIterNew(
+			(*testRaceIter).next,
+			(*testRaceIter).get,
+			(*testRaceIter).err,
+			PipeUnnestFiltered(
+				PipelineNew[[]int, int](),
+				UnnestSortedNew(unnest, func(child *int) int { return *child }, everything),
+			),
+			func(item *int, _ *int) (stop bool, e error) {
+				add(*item)
+				return false, nil
+			},
+		)
+		return func() error { return f(context.Background(), &testRaceIter{items: items}, nil) }
+	}))
+
+	t.Run("PipelineKeysNew", testRaceCheck(want, func(add func(int)) func() error {
+		f := PipelineKeysNew(
+			getPacked,
+			PipeUnnestFiltered(
+				PipelineNew[[]int, int](),
+				UnnestSortedAtNew(
+					func(packed *[]int) (int, error) { return len(*packed), nil },
+					func(packed *[]int, ix int) (int, error) { return (*packed)[ix], nil },
+					func(packed *[]int, ix int, buf *int) error {
+						*buf = (*packed)[ix]
+						return nil
+					},
+					everything,
+				),
+			),
+			func(item *int, _ *int) (stop bool, e error) {
+				add(*item)
+				return false, nil
+			},
+		)
+		return func() error { return f(context.Background(), store, keys(), nil) }
+	}))
+
+	t.Run("GetByKeysNewBatch", testRaceCheck(want, func(add func(int)) func() error {
+		f := GetByKeysNewBatch(
+			getKeys,
+			GetByKeysBatchDecodedNew(
+				GetByKeysBatchFromKey(getByKey),
+				func(packed *[]int) (int, error) { return testRaceSum(*packed), nil },
+				keepItem,
+			),
+			5,
+		)
+		return func() error {
+			var buf int
+			return f(context.Background(), store, nil, nil, &buf, func(
+				val *int,
+				_ *int,
+			) (stop bool, e error) {
+				add(*val)
+				return false, nil
+			})
+		}
+	}))
+
+	t.Run("GetByKeysNewUnnestedBatch", testRaceCheck(want, func(add func(int)) func() error {
+		f := GetByKeysNewUnnestedBatch(
+			func(
+				ctx context.Context,
+				con map[int][]int,
+				keys []int,
+				found func(key int, packed *[]int) error,
+			) error {
+				return GetByKeysBatchFromKey(getByKey)(ctx, con, nil, keys, nil, found)
+			},
+			5,
+			unnest,
+			keepPacked,
+			keepItem,
+			consumeNew(add),
+		)
+		return func() error {
+			var buf []int
+			return f(context.Background(), keys(), store, &buf, nil)
+		}
+	}))
+
+	t.Run("GetByKeysNewPrefetch", testRaceCheck(want, func(add func(int)) func() error {
+		return got2run(GetByKeysNewPrefetch(getKeys, getByKey, 4, 8), add)
+	}))
+
+	t.Run("GetByKeysNewRanged", testRaceCheck(want, func(add func(int)) func() error {
+		return got2run(GetByKeysNewRanged(
+			getKeys,
+			less,
+			func(key int) int { return key + 1 },
+			GetByKeyRangeFromKey(getByKey, less, func(key int) int { return key + 1 }),
+		), add)
+	}))
+
+	t.Run("GetByKeysNewPagedAll", testRaceCheck(want, func(add func(int)) func() error {
+		var getPage GetKeysPage[map[int][]int, uint8, int, int, int] = func(
+			_ context.Context,
+			_ map[int][]int,
+			_ *uint8,
+			_ *int,
+			cursor int,
+		) (keys []int, next int, more bool, e error) {
+			for k := cursor; k < cursor+5 && k < testRaceKeys; k++ {
+				keys = append(keys, k)
+			}
+			return keys, cursor + 5, cursor+5 < testRaceKeys, nil
+		}
+		return got2run(GetByKeysNewPagedAll(getPage, getByKey), add)
+	}))
+
+	t.Run("GetKeys", testRaceCheck(3*wantKeys, func(add func(int)) func() error {
+		var even GetKeys[map[int][]int, uint8, int, int] = func(
+			ctx context.Context,
+			con map[int][]int,
+			bucket *uint8,
+			filter *int,
+		) (keys []int, e error) {
+			all, e := getKeys(ctx, con, bucket, filter)
+			for _, key := range all {
+				if 0 == key%2 {
+					keys = append(keys, key)
+				}
+			}
+			return keys, e
+		}
+		var ordered KeySetOps[int] = KeySetOpsOrderedNew(less)
+		var hashed KeySetOps[int] = KeySetOpsHashNew(func(key int) int { return key })
+		// each source gets all keys.
+		var sources []GetKeys[map[int][]int, uint8, int, int] = []GetKeys[
+			map[int][]int,
+			uint8,
+			int,
+			int,
+		]{
+			GetKeysUnion(hashed, true, even, getKeys.Sorted(less)),
+			GetKeysIntersect(ordered, true, getKeys, getKeys),
+			GetKeysUnion(
+				ordered,
+				true,
+				even,
+				GetKeysSubtract(hashed, true, getKeys, even),
+			),
+		}
+		return func() error {
+			for _, source := range sources {
+				keys, e := source(context.Background(), store, nil, nil)
+				if nil != e {
+					return e
+				}
+				add(testRaceSum(keys))
+			}
+			return nil
+		}
+	}))
+}
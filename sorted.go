@@ -0,0 +1,138 @@
+package local
+
+import (
+	"sort"
+)
+
+// SortedRange contains a key range [lbi, ube) and the order of keys.
+type SortedRange[K any] struct {
+	lbi  K
+	ube  K
+	less func(a, b K) bool
+}
+
+// SortedRangeNew creates a SortedRange.
+//
+// # Arguments
+//   - lbi: The lower bound(inclusive).
+//   - ube: The upper bound(exclusive).
+//   - less: Checks if a key is less than other key.
+func SortedRangeNew[K any](lbi, ube K, less func(a, b K) bool) SortedRange[K] {
+	return SortedRange[K]{
+		lbi:  lbi,
+		ube:  ube,
+		less: less,
+	}
+}
+
+// search gets the index range of sorted items using a key accessor.
+func (r SortedRange[K]) search(
+	count int,
+	keyAt func(ix int) (key K, e error),
+) (first, last int, e error) {
+	var err error
+	check := func(bound K) func(int) bool {
+		return func(ix int) bool {
+			key, e := keyAt(ix)
+			if nil != e {
+				err = e
+				return true
+			}
+			return !r.less(key, bound)
+		}
+	}
+	first = sort.Search(count, check(r.lbi))
+	last = first + sort.Search(count-first, func(ix int) bool { return check(r.ube)(first + ix) })
+	return first, last, err
+}
+
+// UnnestSortedNew creates an UnnestFiltered which passes only children in a key range.
+//
+// Children must be sorted by the key(ascending).
+// The first child is found by binary search and the scan stops at the end of the range.
+//
+// The children of each packed item are local to a call;
+// the UnnestFiltered is safe for concurrent use if unnest, key and rangeOf are.
+//
+// # Arguments
+//   - unnest: Gets sorted children from a packed item.
+//   - key: Gets the sort key of a child.
+//   - rangeOf: Gets a key range from a filter.
+func UnnestSortedNew[P, U, F, K any](
+	unnest Unnest[P, U],
+	key func(child *U) K,
+	rangeOf func(filter *F) SortedRange[K],
+) UnnestFiltered[P, U, F] {
+	return func(
+		packed *P,
+		filter *F,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		children, e := unnest(packed)
+		if nil != e {
+			return true, e
+		}
+		var r SortedRange[K] = rangeOf(filter)
+		first, last, _ := r.search(
+			len(children),
+			func(ix int) (K, error) { return key(&children[ix]), nil },
+		)
+		for ix := first; ix < last; ix++ {
+			*buf = children[ix]
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		return false, nil
+	}
+}
+
+// UnnestSortedAtNew creates an UnnestFiltered which passes only children in a key range
+// using random access(children out of the range are not materialized).
+//
+// Children must be sorted by the key(ascending).
+//
+// Only buf(given by the caller) is written;
+// the UnnestFiltered is safe for concurrent use if count, keyAt, at and rangeOf are.
+//
+// # Arguments
+//   - count: Gets the number of children.
+//   - keyAt: Gets the sort key of a child.
+//   - at: Gets a child.
+//   - rangeOf: Gets a key range from a filter.
+func UnnestSortedAtNew[P, U, F, K any](
+	count func(packed *P) (int, error),
+	keyAt func(packed *P, ix int) (K, error),
+	at func(packed *P, ix int, buf *U) error,
+	rangeOf func(filter *F) SortedRange[K],
+) UnnestFiltered[P, U, F] {
+	return func(
+		packed *P,
+		filter *F,
+		buf *U,
+		each func(unnested *U) (stop bool, e error),
+	) (stop bool, e error) {
+		cnt, e := count(packed)
+		if nil != e {
+			return true, e
+		}
+		var r SortedRange[K] = rangeOf(filter)
+		first, last, e := r.search(cnt, func(ix int) (K, error) { return keyAt(packed, ix) })
+		if nil != e {
+			return true, e
+		}
+		for ix := first; ix < last; ix++ {
+			e = at(packed, ix, buf)
+			if nil != e {
+				return true, e
+			}
+			stop, e = each(buf)
+			if nil != e || stop {
+				return true, e
+			}
+		}
+		return false, nil
+	}
+}
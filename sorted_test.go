@@ -0,0 +1,124 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testSortedPacked struct {
+	minute  int32
+	seconds []uint8
+}
+
+type testSortedChild struct {
+	minute int32
+	second uint8
+}
+
+type testSortedFilter struct {
+	lbi uint8
+	ube uint8
+}
+
+func testSortedRange(f *testSortedFilter) SortedRange[uint8] {
+	return SortedRangeNew(f.lbi, f.ube, func(a, b uint8) bool { return a < b })
+}
+
+func TestSorted(t *testing.T) {
+	t.Parallel()
+
+	var packed testSortedPacked = testSortedPacked{
+		minute:  42,
+		seconds: []uint8{0, 3, 3, 7, 10, 15, 20, 20, 30, 59},
+	}
+
+	t.Run("UnnestSortedNew", func(t *testing.T) {
+		t.Parallel()
+
+		var keyChecks int = 0
+		var unnest UnnestFiltered[
+			testSortedPacked, testSortedChild, testSortedFilter,
+		] = UnnestSortedNew(
+			Unnest[testSortedPacked, testSortedChild](func(
+				p *testSortedPacked,
+			) (children []testSortedChild, e error) {
+				for _, second := range p.seconds {
+					children = append(children, testSortedChild{p.minute, second})
+				}
+				return
+			}),
+			func(c *testSortedChild) uint8 {
+				keyChecks += 1
+				return c.second
+			},
+			testSortedRange,
+		)
+
+		var children []testSortedChild
+		run := PipelineSliceNew(
+			PipeUnnestFiltered(PipelineNew[testSortedPacked, testSortedFilter](), unnest),
+			func(c *testSortedChild, _ *testSortedFilter) (stop bool, e error) {
+				children = append(children, *c)
+				return
+			},
+		)
+
+		var filter testSortedFilter = testSortedFilter{lbi: 3, ube: 20}
+		e := run(context.Background(), []testSortedPacked{packed}, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("5 children", assertEq(len(children), 5))
+		t.Run("first", assertEq(children[0].second, 3))
+		t.Run("last", assertEq(children[4].second, 15))
+		t.Run("parent", assertEq(children[4].minute, 42))
+		t.Run("binary search", assertEq(keyChecks <= 8, true))
+
+		children = nil
+		filter = testSortedFilter{lbi: 31, ube: 59}
+		e = run(context.Background(), []testSortedPacked{packed}, &filter)
+		t.Run("no error", assertNil(e))
+		t.Run("no children", assertEq(len(children), 0))
+	})
+
+	t.Run("UnnestSortedAtNew", func(t *testing.T) {
+		t.Parallel()
+
+		var materialized int = 0
+		var invalid bool = false
+		var unnest UnnestFiltered[
+			testSortedPacked, testSortedChild, testSortedFilter,
+		] = UnnestSortedAtNew(
+			func(p *testSortedPacked) (int, error) { return len(p.seconds), nil },
+			func(p *testSortedPacked, ix int) (uint8, error) {
+				if invalid {
+					return 0, testErrorInvalidKey
+				}
+				return p.seconds[ix], nil
+			},
+			func(p *testSortedPacked, ix int, buf *testSortedChild) error {
+				materialized += 1
+				*buf = testSortedChild{p.minute, p.seconds[ix]}
+				return nil
+			},
+			testSortedRange,
+		)
+
+		var buf testSortedChild
+		var children []testSortedChild
+		var filter testSortedFilter = testSortedFilter{lbi: 20, ube: 60}
+		_, e := unnest(&packed, &filter, &buf, func(c *testSortedChild) (stop bool, e error) {
+			children = append(children, *c)
+			return 3 == len(children), nil
+		})
+		t.Run("no error", assertNil(e))
+		t.Run("3 children", assertEq(len(children), 3))
+		t.Run("first", assertEq(children[0].second, 20))
+		t.Run("3 materialized", assertEq(materialized, 3))
+
+		invalid = true
+		_, e = unnest(&packed, &filter, &buf, func(c *testSortedChild) (bool, error) {
+			return false, nil
+		})
+		t.Run("key error", assertEq(errors.Is(e, testErrorInvalidKey), true))
+	})
+}
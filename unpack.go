@@ -9,6 +9,9 @@ type Unpack[P, U any] func(packed P) (unpacked []U, e error)
 
 // NewAll creates a closure which gets unpacked items.
 //
+// Unpacked items are collected for each call;
+// the closure is safe for concurrent use if all and u are.
+//
 // # Arguments
 //   - all: Gets packed items.
 func (u Unpack[P, U]) NewAll(
@@ -33,6 +36,8 @@ func (u Unpack[P, U]) NewAll(
 
 // RemoteFilterNewUnpacked creates a closure which gets unpacked items.
 //
+// Like RemoteFilterNewDecoded, it is safe for concurrent use if unpack and remote are.
+//
 // # Arguments
 //   - unpack: Gets unpacked items from a packed item.
 //   - remote: Gets packed items.
@@ -56,3 +61,34 @@ func RemoteFilterNewUnpacked[P, U, F any](
 		)(b)
 	}
 }
+
+// NewAllWithPolicy creates a closure which gets unpacked items using an ErrorPolicy.
+//
+// Each call fills its own ErrorReport; the closure is safe for concurrent use if all and u are.
+//
+// # Arguments
+//   - all: Gets packed items.
+//   - policy: Decides what to do with an item which can not be unpacked.
+func (u Unpack[P, U]) NewAllWithPolicy(
+	all func(context.Context, Bucket) (packed []P, e error),
+	policy ErrorPolicy,
+) func(context.Context, Bucket) (unpacked []U, report ErrorReport, e error) {
+	return func(ctx context.Context, b Bucket) (unpacked []U, report ErrorReport, e error) {
+		packed, e := all(ctx, b)
+		if nil != e {
+			return nil, report, e
+		}
+		for ix, packedItem := range packed {
+			chunk, e := u(packedItem)
+			if nil != e {
+				e = report.Handle(policy, ItemErrorNew(b, ix, nil, e))
+				if nil != e {
+					return nil, report, e
+				}
+				continue
+			}
+			unpacked = append(unpacked, chunk...)
+		}
+		return
+	}
+}
@@ -183,3 +183,50 @@ func TestUnpack(t *testing.T) {
 		})
 	})
 }
+
+func TestUnpackWithPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unpack", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("NewAllWithPolicy", func(t *testing.T) {
+			t.Parallel()
+
+			getPacked := func(_ context.Context, _b Bucket) ([]testPackedRow, error) {
+				return []testPackedRow{
+					{key: 0x01, val: 0x0123456789abcdef},
+					{key: 0x00, val: 0x0123456789abcdef},
+					{key: 0x02, val: 0x0123456789abcdef},
+				}, nil
+			}
+			var unpack Unpack[testPackedRow, testUnpackedRow] = func(
+				packed testPackedRow,
+			) (unpacked []testUnpackedRow, e error) {
+				if 0 == packed.key {
+					return nil, testErrorInvalidKey
+				}
+				return packed.unpack(), nil
+			}
+
+			t.Run("fail fast", func(t *testing.T) {
+				t.Parallel()
+
+				getUnpacked := unpack.NewAllWithPolicy(getPacked, ErrorPolicyFailFast)
+				_, _, e := getUnpacked(context.Background(), BucketNew(""))
+				t.Run("error", assertEq(nil != e, true))
+			})
+
+			t.Run("skip collect", func(t *testing.T) {
+				t.Parallel()
+
+				getUnpacked := unpack.NewAllWithPolicy(getPacked, ErrorPolicySkipCollect)
+				unpacked, report, e := getUnpacked(context.Background(), BucketNew(""))
+				t.Run("no error", assertNil(e))
+				t.Run("8 items", assertEq(len(unpacked), 8))
+				t.Run("single skipped", assertEq(report.Skipped(), 1))
+				t.Run("index", assertEq(report.Errors()[0].Index(), 1))
+			})
+		})
+	})
+}